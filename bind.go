@@ -0,0 +1,290 @@
+package scuter
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMalformedRequestValue = Error{
+		Name:    "malformed-request-value",
+		Message: "The value could not be parsed.",
+	}
+	ErrMalformedRequestForm = Error{
+		Fields:  []string{"form"},
+		Name:    "malformed-request-form",
+		Message: "The form data could not be parsed.",
+	}
+)
+
+// Bind populates the exported fields of the struct pointed to by v from the parts of the request named by each
+// field's struct tag: `path:"id"` (see http.Request.PathValue), `query:"page"`, `header:"X-Version"`, and
+// `form:"name"`. Supported field types include strings, integers, booleans, floats, time.Duration, time.Time (with
+// an optional `layout:"2006-01-02"` tag, RFC 3339 otherwise), any encoding.TextUnmarshaler, and pointers and slices
+// of all of those. Values which are absent leave the field untouched. Each value which cannot be parsed results in
+// an ErrMalformedRequestValue whose Fields identify the offending value (ie. "query.page"), and all such errors are
+// gathered into a single JSON error response which can be sent to the client with Flush. Form values are parsed from
+// the body (unless already parsed, see http.Request.ParseForm) within the limit established by SetDefaultReadOptions
+// (see Read.MaxBytes), beyond which the response is ErrRequestBodyTooLarge with 413 Request Entity Too Large; bodies
+// carrying file uploads are better read with ReadMultipartRequestBody. Bind panics if v isn't a pointer to a struct,
+// or if any of its tagged fields is of an unsupported type (whether or not the request has a value for it).
+func Bind(request *http.Request, v any) (ResponseOption, bool) {
+	return (&binder{request: request, sources: bindSources}).bind(v)
+}
+
+type binder struct {
+	request *http.Request
+	sources []string
+	query   url.Values
+	form    url.Values
+	errs    []Error
+	failure ResponseOption // a failure to read the body, which takes precedence over errs
+}
+
+func (this *binder) bind(v any) (ResponseOption, bool) {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("scuter: Bind requires a pointer to a struct, not %T", v))
+	}
+	checkBindable(target.Type().Elem())
+	this.bindStruct(target.Elem())
+	if this.failure != nil {
+		return this.failure, false
+	}
+	if len(this.errs) > 0 {
+		return Response.JSONErrors(http.StatusBadRequest, this.errs...), false
+	}
+	return nil, true
+}
+
+// checkBindable panics if any tagged field of target (a struct type) is of a type which Bind doesn't support, such
+// that the mistake surfaces the first time the type is bound rather than only once a request carries that field.
+func checkBindable(target reflect.Type) {
+	if _, checked := bindableTypes.Load(target); checked {
+		return
+	}
+	for x := 0; x < target.NumField(); x++ {
+		field := target.Field(x)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			checkBindable(field.Type)
+			continue
+		}
+		if !field.IsExported() || !isBindTagged(field) {
+			continue
+		}
+		if fieldType := field.Type; !isBindableType(fieldType) &&
+			(fieldType.Kind() != reflect.Slice || !isBindableType(fieldType.Elem())) {
+			panic(fmt.Sprintf("scuter: Bind does not support fields of type %s (%s.%s)", fieldType, target, field.Name))
+		}
+	}
+	bindableTypes.Store(target, struct{}{})
+}
+func isBindTagged(field reflect.StructField) bool {
+	for _, source := range bindSources {
+		if _, ok := field.Tag.Lookup(source); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isBindableType reports whether bindValue can parse a single value into target.
+func isBindableType(target reflect.Type) bool {
+	if target.Kind() == reflect.Pointer {
+		return isBindableType(target.Elem())
+	}
+	if reflect.PointerTo(target).Implements(textUnmarshalerType) || target == timeType {
+		return true
+	}
+	switch target.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func (this *binder) bindStruct(target reflect.Value) {
+	for x := 0; x < target.NumField(); x++ {
+		field := target.Type().Field(x)
+		value := target.Field(x)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			this.bindStruct(value)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		for _, source := range this.sources {
+			if name, ok := field.Tag.Lookup(source); ok {
+				this.bindField(source, name, field.Tag.Get("layout"), value)
+			}
+		}
+	}
+}
+func (this *binder) bindField(source, name, layout string, target reflect.Value) {
+	raw := this.values(source, name)
+	if len(raw) == 0 {
+		return
+	}
+	if problem := bindValues(target, raw, layout); problem != "" {
+		this.errs = append(this.errs, Error{
+			Fields:  []string{source + "." + name},
+			Name:    ErrMalformedRequestValue.Name,
+			Message: problem,
+		})
+	}
+}
+func (this *binder) values(source, name string) []string {
+	switch source {
+	case "path":
+		if value := this.request.PathValue(name); value != "" {
+			return []string{value}
+		}
+	case "query":
+		if this.query == nil {
+			this.query = this.request.URL.Query()
+		}
+		return this.query[name]
+	case "header":
+		return this.request.Header.Values(name)
+	case "form":
+		if this.form == nil {
+			this.parseForm()
+		}
+		return this.form[name]
+	}
+	return nil
+}
+
+// parseForm parses the body of the request (unless already parsed) as the ReadXxxRequestBody functions read it,
+// within the limit established by SetDefaultReadOptions (see Read.MaxBytes) and honoring any Content-Encoding.
+func (this *binder) parseForm() {
+	if this.request.PostForm == nil {
+		this.parseBody()
+	}
+	this.form = this.request.PostForm
+	if this.form == nil {
+		this.form = make(url.Values)
+	}
+}
+func (this *binder) parseBody() {
+	result, ok := newReadConfig(nil).prepareBody(this.request)
+	if !ok {
+		this.failure = result
+		return
+	}
+	err := this.request.ParseForm() // whose errors ParseMultipartForm would discard for url-encoded bodies
+	if err == nil {
+		err = this.request.ParseMultipartForm(defaultMaxMultipartMemory)
+	}
+	switch tooLarge := new(http.MaxBytesError); {
+	case err == nil || errors.Is(err, http.ErrNotMultipart):
+	case errors.As(err, &tooLarge):
+		this.failure = Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge)
+	default:
+		this.errs = append(this.errs, ErrMalformedRequestForm)
+	}
+}
+
+// bindValues parses raw into target, returning a description of the problem, if any.
+func bindValues(target reflect.Value, raw []string, layout string) (problem string) {
+	if target.Kind() == reflect.Slice && !implementsTextUnmarshaler(target) {
+		slice := reflect.MakeSlice(target.Type(), len(raw), len(raw))
+		for x, value := range raw {
+			if problem = bindValue(slice.Index(x), value, layout); problem != "" {
+				return problem
+			}
+		}
+		target.Set(slice)
+		return ""
+	}
+	return bindValue(target, raw[0], layout)
+}
+func bindValue(target reflect.Value, raw, layout string) (problem string) {
+	if target.Kind() == reflect.Pointer {
+		element := reflect.New(target.Type().Elem())
+		if problem = bindValue(element.Elem(), raw, layout); problem != "" {
+			return problem
+		}
+		target.Set(element)
+		return ""
+	}
+	if target.Type() == timeType && layout != "" {
+		parsed, err := time.Parse(layout, raw)
+		if err != nil {
+			return fmt.Sprintf("The value must be a time formatted as %q.", layout)
+		}
+		target.Set(reflect.ValueOf(parsed))
+		return ""
+	}
+	if implementsTextUnmarshaler(target) {
+		unmarshaler := target.Addr().Interface().(encoding.TextUnmarshaler)
+		if err := unmarshaler.UnmarshalText([]byte(raw)); err != nil {
+			return fmt.Sprintf("The value could not be parsed as %s.", target.Type())
+		}
+		return ""
+	}
+	if target.Type() == durationType {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return "The value must be a duration (ie. '1m30s')."
+		}
+		target.SetInt(int64(parsed))
+		return ""
+	}
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "The value must be a boolean."
+		}
+		target.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, target.Type().Bits())
+		if err != nil {
+			return "The value must be an integer."
+		}
+		target.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, target.Type().Bits())
+		if err != nil {
+			return "The value must be a non-negative integer."
+		}
+		target.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, target.Type().Bits())
+		if err != nil {
+			return "The value must be a number."
+		}
+		target.SetFloat(parsed)
+	default:
+		panic(fmt.Sprintf("scuter: Bind does not support fields of type %s", target.Type()))
+	}
+	return ""
+}
+func implementsTextUnmarshaler(target reflect.Value) bool {
+	return target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType)
+}
+
+var (
+	bindSources   = []string{"path", "query", "header", "form"}
+	bindableTypes sync.Map
+
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+const defaultMaxMultipartMemory = 32 << 20
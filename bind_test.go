@@ -0,0 +1,159 @@
+package scuter
+
+import (
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+type bindModel struct {
+	bindEmbedded
+	ID       uint64        `path:"id"`
+	Page     int           `query:"page"`
+	Active   bool          `query:"active"`
+	Ratio    float64       `query:"ratio"`
+	Tags     []string      `query:"tag"`
+	Counts   []int8        `query:"count"`
+	Timeout  time.Duration `query:"timeout"`
+	Since    time.Time     `query:"since"`
+	Day      time.Time     `query:"day" layout:"2006-01-02"`
+	Limit    *uint16       `query:"limit"`
+	Address  netip.Addr    `header:"X-Address"`
+	Version  string        `header:"X-Version"`
+	Name     string        `form:"name"`
+	Untagged string
+	ignored  string `query:"ignored"`
+}
+type bindEmbedded struct {
+	Region string `query:"region"`
+}
+
+func TestBind(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodPost, "/", Request.With(
+		Request.PathValue("id", "42"),
+		Request.Query("page", "-3"),
+		Request.Query("active", "true"),
+		Request.Query("ratio", "0.5"),
+		Request.Query("tag", "a"),
+		Request.Query("tag", "b"),
+		Request.Query("count", "1"),
+		Request.Query("count", "2"),
+		Request.Query("timeout", "1m30s"),
+		Request.Query("since", "2025-01-02T03:04:05Z"),
+		Request.Query("day", "2025-01-02"),
+		Request.Query("limit", "7"),
+		Request.Query("region", "west"),
+		Request.Query("ignored", "nope"),
+		Request.Header("X-Address", "127.0.0.1"),
+		Request.Header("X-Version", "v2"),
+		Request.Header(headerContentType, "application/x-www-form-urlencoded"),
+		Request.Body(strings.NewReader(url.Values{"name": {"Gopher"}}.Encode())),
+	))
+	var model bindModel
+
+	actual, ok := Bind(request, &model)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, model.ID, should.Equal, uint64(42))
+	should.So(t, model.Page, should.Equal, -3)
+	should.So(t, model.Active, should.BeTrue)
+	should.So(t, model.Ratio, should.Equal, 0.5)
+	should.So(t, model.Tags, should.Equal, []string{"a", "b"})
+	should.So(t, model.Counts, should.Equal, []int8{1, 2})
+	should.So(t, model.Timeout, should.Equal, 90*time.Second)
+	should.So(t, model.Since, should.Equal, time.Date(2025, time.January, 2, 3, 4, 5, 0, time.UTC))
+	should.So(t, model.Day, should.Equal, time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC))
+	should.So(t, *model.Limit, should.Equal, uint16(7))
+	should.So(t, model.Region, should.Equal, "west")
+	should.So(t, model.Address, should.Equal, netip.MustParseAddr("127.0.0.1"))
+	should.So(t, model.Version, should.Equal, "v2")
+	should.So(t, model.Name, should.Equal, "Gopher")
+	should.So(t, model.ignored, should.Equal, "")
+}
+func TestBind_AbsentValuesUntouched(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodGet, "/")
+	model := bindModel{Page: 1, Version: "v1"}
+
+	actual, ok := Bind(request, &model)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, model.Page, should.Equal, 1)
+	should.So(t, model.Version, should.Equal, "v1")
+	should.So(t, model.Limit, should.BeNil)
+}
+func TestBind_MalformedValues(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodGet, "/", Request.With(
+		Request.PathValue("id", "-1"),
+		Request.Query("page", "abc"),
+		Request.Query("count", "1"),
+		Request.Query("count", "1000"),
+		Request.Query("day", "01/02/2025"),
+		Request.Header("X-Address", "not-an-ip"),
+	))
+	var model bindModel
+
+	actual, ok := Bind(request, &model)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest,
+		Error{Fields: []string{"path.id"}, Name: "malformed-request-value", Message: "The value must be a non-negative integer."},
+		Error{Fields: []string{"query.page"}, Name: "malformed-request-value", Message: "The value must be an integer."},
+		Error{Fields: []string{"query.count"}, Name: "malformed-request-value", Message: "The value must be an integer."},
+		Error{Fields: []string{"query.day"}, Name: "malformed-request-value", Message: `The value must be a time formatted as "2006-01-02".`},
+		Error{Fields: []string{"header.X-Address"}, Name: "malformed-request-value", Message: "The value could not be parsed as netip.Addr."},
+	), actual)
+}
+func TestBind_FormBodyTooLarge(t *testing.T) {
+	defer SetDefaultReadOptions()
+	SetDefaultReadOptions(Read.MaxBytes(8))
+	request := NewTestRequest(t.Context(), http.MethodPost, "/", Request.With(
+		Request.Header(headerContentType, "application/x-www-form-urlencoded"),
+		Request.Body(strings.NewReader(url.Values{"name": {"Gopher"}}.Encode())),
+	))
+	request.ContentLength = -1 // as with a chunked body
+	var model bindModel
+
+	actual, ok := Bind(request, &model)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+	should.So(t, model.Name, should.Equal, "")
+}
+func TestBind_ServeMuxPattern(t *testing.T) {
+	var model struct {
+		ID uint64 `path:"id"`
+	}
+	router := http.NewServeMux()
+	router.HandleFunc("GET /tasks/{id}", func(_ http.ResponseWriter, request *http.Request) {
+		_, _ = Bind(request, &model)
+	})
+
+	router.ServeHTTP(nil, NewTestRequest(t.Context(), http.MethodGet, "/tasks/42"))
+
+	should.So(t, model.ID, should.Equal, uint64(42))
+}
+func TestBind_NotStructPointer(t *testing.T) {
+	defer func() { should.So(t, recover(), should.NOT.BeNil) }()
+	var model bindModel
+	_, _ = Bind(NewTestRequest(t.Context(), http.MethodGet, "/"), model)
+}
+func TestBind_UnsupportedFieldType(t *testing.T) {
+	defer func() {
+		should.So(t, recover(), should.Equal,
+			"scuter: Bind does not support fields of type map[string]string (scuter.unsupportedBindModel.Labels)")
+	}()
+	var model unsupportedBindModel
+	_, _ = Bind(NewTestRequest(t.Context(), http.MethodGet, "/"), &model) // no request carries "label"
+}
+
+type unsupportedBindModel struct {
+	Page   int               `query:"page"`
+	Labels map[string]string `query:"label"`
+}
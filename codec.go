@@ -0,0 +1,161 @@
+package scuter
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Codec encodes and decodes values for a particular media type.
+type Codec interface {
+	// MediaType returns the value of the Content-Type header to be sent with encoded values
+	// (ie. "application/json; charset=utf-8"). Only the media type itself, without parameters, is
+	// considered when looking up a Codec for a Content-Type.
+	MediaType() string
+	Decode(io.Reader, any) error
+	Encode(io.Writer, any) error
+}
+
+// RequestBodyDecoder is implemented by Codecs which decode request bodies themselves, rather than having
+// ReadRequestBody call Decode, ie. JSONCodec, which reads them as ReadJSONRequestBody does (honoring Read.Strict and
+// pinpointing malformed content).
+type RequestBodyDecoder interface {
+	DecodeRequestBody(request *http.Request, v any, options ...ReadOption) (ResponseOption, bool)
+}
+
+// Codecs is a registry of Codec values keyed by media type.
+type Codecs struct {
+	lock    sync.RWMutex
+	entries []codecEntry
+}
+type codecEntry struct {
+	mediaType string
+	codec     Codec
+}
+
+func NewCodecs(codecs ...Codec) *Codecs {
+	this := new(Codecs)
+	for _, codec := range codecs {
+		this.Register(codec)
+	}
+	return this
+}
+
+// Register adds the codec to the registry, replacing any codec previously registered for the same media type.
+func (this *Codecs) Register(codec Codec) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	entry := codecEntry{mediaType: baseMediaType(codec.MediaType()), codec: codec}
+	for x, registered := range this.entries {
+		if registered.mediaType == entry.mediaType {
+			this.entries[x] = entry
+			return
+		}
+	}
+	this.entries = append(this.entries, entry)
+}
+
+// Lookup returns the codec registered for the media type of the provided Content-Type value. Media types with a
+// structured syntax suffix (ie. "application/vnd.api+json") fall back to the codec registered for the suffix
+// (ie. "application/json").
+func (this *Codecs) Lookup(contentType string) (Codec, bool) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	mediaType := baseMediaType(contentType)
+	if codec, ok := this.lookup(mediaType); ok {
+		return codec, true
+	}
+	if index := strings.LastIndexByte(mediaType, '+'); index >= 0 {
+		return this.lookup("application/" + mediaType[index+1:])
+	}
+	return nil, false
+}
+func (this *Codecs) lookup(mediaType string) (Codec, bool) {
+	for _, entry := range this.entries {
+		if entry.mediaType == mediaType {
+			return entry.codec, true
+		}
+	}
+	return nil, false
+}
+
+// DefaultCodecs is the registry consulted by ReadRequestBody and Flush.
+var DefaultCodecs = NewCodecs(JSONCodec{}, XMLCodec{})
+
+// RegisterCodec adds the codec to DefaultCodecs, replacing any codec previously registered for the same media type
+// (including the built-in JSON and XML codecs).
+func RegisterCodec(codec Codec) { DefaultCodecs.Register(codec) }
+
+// JSONCodec is the Codec for "application/json", built on encoding/json. The zero value is ready to use, and the
+// fields only affect encoding (see Flushing for establishing them with a Flusher).
+type JSONCodec struct {
+	// ContentType, when not empty, replaces the MediaType (ie. "application/json; charset=utf-8").
+	ContentType string
+
+	// Prefix and Indent, when either is not empty, cause values to be indented (see json.Encoder.SetIndent).
+	Prefix, Indent string
+
+	// DisableHTMLEscaping leaves problematic HTML characters unescaped (see json.Encoder.SetEscapeHTML).
+	DisableHTMLEscaping bool
+
+	// Marshal, when not nil, replaces encoding/json (and with it the settings above) for encoding values, each of
+	// which is followed by a newline, as with json.Encoder.
+	Marshal func(any) ([]byte, error)
+}
+
+func (this JSONCodec) MediaType() string {
+	if this.ContentType != "" {
+		return this.ContentType
+	}
+	return jsonContentType
+}
+func (JSONCodec) DecodeRequestBody(request *http.Request, v any, options ...ReadOption) (ResponseOption, bool) {
+	return ReadJSONRequestBody(request, v, options...)
+}
+func (JSONCodec) Decode(reader io.Reader, v any) error {
+	return json.NewDecoder(reader).Decode(v) // FUTURE: upgrade to json/v2's json.UnmarshalRead
+}
+func (this JSONCodec) Encode(writer io.Writer, v any) error {
+	if this.Marshal != nil {
+		return this.marshal(writer, v)
+	}
+	encoder := json.NewEncoder(writer) // FUTURE: upgrade to json/v2's MarshalWrite
+	encoder.SetIndent(this.Prefix, this.Indent)
+	encoder.SetEscapeHTML(!this.DisableHTMLEscaping)
+	return encoder.Encode(v)
+}
+func (this JSONCodec) marshal(writer io.Writer, v any) error {
+	data, err := this.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(data); err != nil {
+		return err
+	}
+	_, err = writer.Write(newline)
+	return err
+}
+
+// XMLCodec is the Codec for "application/xml", built on encoding/xml.
+type XMLCodec struct{}
+
+func (XMLCodec) MediaType() string { return xmlContentType }
+func (XMLCodec) Decode(reader io.Reader, v any) error {
+	return xml.NewDecoder(reader).Decode(v)
+}
+func (XMLCodec) Encode(writer io.Writer, v any) error {
+	return xml.NewEncoder(writer).Encode(v)
+}
+
+// baseMediaType returns the lower-cased media type of the Content-Type value, without parameters.
+func baseMediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+var newline = []byte("\n")
+
+const xmlContentType = "application/xml; charset=utf-8"
@@ -0,0 +1,156 @@
+package scuter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+type codecModel struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestCodecsLookup(t *testing.T) {
+	codecs := NewCodecs(JSONCodec{}, XMLCodec{})
+
+	assertLookup(t, codecs, "application/json", JSONCodec{})
+	assertLookup(t, codecs, "Application/JSON; charset=utf-8", JSONCodec{})
+	assertLookup(t, codecs, "application/vnd.api+json", JSONCodec{})
+	assertLookup(t, codecs, "application/xml", XMLCodec{})
+	assertLookup(t, codecs, "application/atom+xml", XMLCodec{})
+	assertLookup(t, codecs, "text/plain", nil)
+	assertLookup(t, codecs, "", nil)
+}
+func TestCodecsRegister_Replaces(t *testing.T) {
+	codecs := NewCodecs(JSONCodec{}, XMLCodec{})
+	replacement := &fakeCodec{mediaType: "application/json"}
+
+	codecs.Register(replacement)
+
+	assertLookup(t, codecs, "application/json", replacement)
+	should.So(t, len(codecs.entries), should.Equal, 2)
+}
+func assertLookup(t *testing.T, codecs *Codecs, contentType string, expected Codec) {
+	t.Helper()
+	codec, ok := codecs.Lookup(contentType)
+	should.So(t, ok, should.Equal, expected != nil)
+	should.So(t, codec, should.Equal, expected)
+}
+
+func TestReadRequestBody_XML(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header(headerContentType, "application/xml"),
+		Request.Body(strings.NewReader(`<codecModel><name>Gopher</name></codecModel>`)),
+	))
+	var model codecModel
+
+	actual, ok := ReadRequestBody(request, &model)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, model.Name, should.Equal, "Gopher")
+}
+func TestReadRequestBody_JSON(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.JSONBody(map[string]any{"name": "Gopher", "extra": 1}))
+	var model codecModel
+
+	actual, ok := ReadRequestBody(request, &model, Read.Strict(true))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest,
+		Error{Fields: []string{"body.extra"}, Name: "unknown-request-field", Message: "The field is not recognized."},
+	), actual)
+}
+func TestReadRequestBody_MalformedXML(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header(headerContentType, "application/xml"),
+		Request.Body(strings.NewReader(`<codecModel>`)),
+	))
+	var model codecModel
+
+	actual, ok := ReadRequestBody(request, &model)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, ErrInvalidRequestBody), actual)
+}
+func TestReadRequestBody_RequestBodyDecoder(t *testing.T) {
+	codecs := DefaultCodecs
+	defer func() { DefaultCodecs = codecs }()
+	DefaultCodecs = NewCodecs(decodingCodec{&fakeCodec{mediaType: "text/csv"}})
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.Header(headerContentType, "text/csv"))
+	var model codecModel
+
+	actual, ok := ReadRequestBody(request, &model)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusTeapot, Error{Name: "decoded-itself"}), actual)
+}
+func TestReadRequestBody_UnsupportedContentType(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.Header(headerContentType, "text/csv"))
+
+	actual, ok := ReadRequestBody(request, new(codecModel))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), actual)
+}
+func TestReadRequestBody_TooLarge(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header(headerContentType, "application/xml"),
+		Request.Body(strings.NewReader(`<codecModel><name>Gopher</name></codecModel>`)),
+	))
+
+	actual, ok := ReadRequestBody(request, new(codecModel), Read.MaxBytes(8))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+
+func TestResponseBody_DefaultsToJSON(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Flush(recorder, Response.Body(codecModel{Name: "Gopher"}))
+	should.So(t, recorder.Header().Get(headerContentType), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal, `{"name":"Gopher"}`+"\n")
+}
+func TestResponseBody_XML(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Flush(recorder, Response.ContentType("application/xml"), Response.Body(codecModel{Name: "Gopher"}))
+	should.So(t, recorder.Header().Get(headerContentType), should.Equal, "application/xml")
+	should.So(t, recorder.Body.String(), should.Equal, `<codecModel><name>Gopher</name></codecModel>`)
+}
+func TestResponseBody_UnregisteredContentType(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Flush(recorder, Response.ContentType("text/csv"), Response.Body(codecModel{Name: "Gopher"}))
+	should.So(t, recorder.Code, should.Equal, http.StatusInternalServerError)
+	should.So(t, recorder.Header().Get(headerContentType), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal,
+		`{"errors":[{"name":"internal-server-error","message":"Internal Server Error"}]}`+"\n")
+}
+func TestRegisterCodec(t *testing.T) {
+	defer RegisterCodec(JSONCodec{})
+	RegisterCodec(&fakeCodec{mediaType: "application/json"})
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.JSONBody(codecModel{Name: "Gopher"}))
+
+	should.So(t, recorder.Body.String(), should.Equal, "fake: {Gopher}")
+}
+
+type decodingCodec struct{ *fakeCodec }
+
+func (decodingCodec) DecodeRequestBody(*http.Request, any, ...ReadOption) (ResponseOption, bool) {
+	return Response.JSONErrors(http.StatusTeapot, Error{Name: "decoded-itself"}), false
+}
+
+type fakeCodec struct{ mediaType string }
+
+func (this *fakeCodec) MediaType() string           { return this.mediaType }
+func (this *fakeCodec) Decode(io.Reader, any) error { return nil }
+func (this *fakeCodec) Encode(writer io.Writer, v any) error {
+	_, err := fmt.Fprintf(writer, "fake: %v", v)
+	return err
+}
@@ -0,0 +1,110 @@
+package scuter
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorMap translates the errors of an application (ie. the result of a command) into responses, according to the
+// mappings with which it was constructed (see Mapping and MapErrorAs), which are consulted in the order supplied.
+// An ErrorMap is safe for concurrent use.
+type ErrorMap struct {
+	mappings []errorMapping
+	fallback errorMapping
+	logger   ErrorLogger
+}
+
+// ErrorLogger receives unmapped errors (see Mapping.Logger). It is satisfied by *log.Logger.
+type ErrorLogger interface {
+	Printf(format string, args ...any)
+}
+
+type errorMapping struct {
+	status int
+	match  func(error) (Error, bool)
+}
+
+// NewErrorMap returns an ErrorMap configured with the supplied options. Unless otherwise configured (see
+// Mapping.Fallback), unmapped errors result in ErrInternalServerError with 500 Internal Server Error.
+func NewErrorMap(options ...ErrorMapOption) *ErrorMap {
+	this := &ErrorMap{fallback: errorMapping{
+		status: http.StatusInternalServerError,
+		match:  func(error) (Error, bool) { return ErrInternalServerError, true },
+	}}
+	for _, option := range options {
+		if option != nil {
+			option(this)
+		}
+	}
+	return this
+}
+
+// Response returns the response of the first mapping matching the error (including any error it wraps), or else
+// that of the fallback, after passing the error to the logger (if any). A nil error results in nil.
+func (this *ErrorMap) Response(err error) ResponseOption {
+	if err == nil {
+		return nil
+	}
+	for _, mapping := range this.mappings {
+		if failure, ok := mapping.match(err); ok {
+			return Response.JSONErrors(mapping.status, failure)
+		}
+	}
+	if this.logger != nil {
+		this.logger.Printf("[WARN] unmapped error (%T): %v", err, err)
+	}
+	failure, _ := this.fallback.match(err)
+	return Response.JSONErrors(this.fallback.status, failure)
+}
+
+// ErrorMapOption is a callback func with an opportunity to modify the *ErrorMap being constructed.
+type ErrorMapOption func(*ErrorMap)
+
+// Mapping is the 'namespace' for all methods that return an ErrorMapOption (see also MapErrorAs).
+var Mapping mappingSingleton
+
+type mappingSingleton struct{}
+
+// Is maps errors matching the target (see errors.Is) to the failure with the status code.
+func (mappingSingleton) Is(target error, status int, failure Error) ErrorMapOption {
+	return Mapping.IsFunc(target, status, func(error) Error { return failure })
+}
+
+// IsFunc maps errors matching the target (see errors.Is) to the failure built from the error with the status code.
+func (mappingSingleton) IsFunc(target error, status int, build func(error) Error) ErrorMapOption {
+	return func(this *ErrorMap) {
+		this.mappings = append(this.mappings, errorMapping{status: status, match: func(err error) (Error, bool) {
+			if errors.Is(err, target) {
+				return build(err), true
+			}
+			return Error{}, false
+		}})
+	}
+}
+
+// Fallback maps all errors not otherwise mapped to the failure with the status code.
+func (mappingSingleton) Fallback(status int, failure Error) ErrorMapOption {
+	return func(this *ErrorMap) {
+		this.fallback = errorMapping{status: status, match: func(error) (Error, bool) { return failure, true }}
+	}
+}
+
+// Logger registers the logger to receive every error which is not otherwise mapped (ie. before the fallback is used).
+func (mappingSingleton) Logger(logger ErrorLogger) ErrorMapOption {
+	return func(this *ErrorMap) {
+		this.logger = logger
+	}
+}
+
+// MapErrorAs maps errors of type T (see errors.As) to the failure built from the error with the status code.
+func MapErrorAs[T error](status int, build func(T) Error) ErrorMapOption {
+	return func(this *ErrorMap) {
+		this.mappings = append(this.mappings, errorMapping{status: status, match: func(err error) (Error, bool) {
+			var target T
+			if errors.As(err, &target) {
+				return build(target), true
+			}
+			return Error{}, false
+		}})
+	}
+}
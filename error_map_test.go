@@ -0,0 +1,86 @@
+package scuter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+var (
+	errTooHard  = errors.New("too hard")
+	errNotFound = errors.New("not found")
+)
+
+type recordingLogger struct{ lines []string }
+
+func (this *recordingLogger) Printf(format string, args ...any) {
+	this.lines = append(this.lines, fmt.Sprintf(format, args...))
+}
+
+func TestErrorMap_Is(t *testing.T) {
+	failure := Error{Fields: []string{"details"}, Name: "task-too-hard"}
+	mapper := NewErrorMap(
+		Mapping.Is(errTooHard, http.StatusTeapot, failure),
+		Mapping.Is(errNotFound, http.StatusNotFound, Error{Name: "not-found"}),
+	)
+
+	assertResponseEqual(t, Response.JSONErrors(http.StatusTeapot, failure), mapper.Response(errTooHard))
+	assertResponseEqual(t, Response.JSONErrors(http.StatusTeapot, failure),
+		mapper.Response(fmt.Errorf("handling: %w", errTooHard)))
+	assertResponseEqual(t, Response.JSONErrors(http.StatusNotFound, Error{Name: "not-found"}),
+		mapper.Response(errors.Join(errors.New("other"), errNotFound)))
+}
+func TestErrorMap_FirstMappingWins(t *testing.T) {
+	mapper := NewErrorMap(
+		Mapping.Is(errTooHard, http.StatusConflict, Error{Name: "first"}),
+		Mapping.Is(errTooHard, http.StatusTeapot, Error{Name: "second"}),
+	)
+
+	assertResponseEqual(t, Response.JSONErrors(http.StatusConflict, Error{Name: "first"}), mapper.Response(errTooHard))
+}
+func TestErrorMap_IsFunc(t *testing.T) {
+	mapper := NewErrorMap(Mapping.IsFunc(errTooHard, http.StatusTeapot, func(err error) Error {
+		return Error{Name: "task-too-hard", Message: err.Error()}
+	}))
+
+	actual := mapper.Response(fmt.Errorf("task 42: %w", errTooHard))
+
+	assertResponseEqual(t, Response.JSONErrors(http.StatusTeapot, Error{Name: "task-too-hard", Message: "task 42: too hard"}), actual)
+}
+func TestErrorMap_As(t *testing.T) {
+	mapper := NewErrorMap(MapErrorAs(http.StatusNotFound, func(err *fs.PathError) Error {
+		return Error{Fields: []string{"path." + err.Path}, Name: "not-found"}
+	}))
+
+	actual := mapper.Response(fmt.Errorf("opening: %w", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}))
+
+	assertResponseEqual(t, Response.JSONErrors(http.StatusNotFound, Error{Fields: []string{"path.x"}, Name: "not-found"}), actual)
+}
+func TestErrorMap_Nil(t *testing.T) {
+	logger := &recordingLogger{}
+	mapper := NewErrorMap(Mapping.Logger(logger))
+
+	should.So(t, mapper.Response(nil), should.BeNil)
+	should.So(t, logger.lines, should.BeNil)
+}
+func TestErrorMap_DefaultFallback(t *testing.T) {
+	logger := &recordingLogger{}
+	mapper := NewErrorMap(Mapping.Is(errTooHard, http.StatusTeapot, Error{}), Mapping.Logger(logger))
+
+	actual := mapper.Response(errNotFound)
+
+	assertResponseEqual(t, Response.JSONErrors(http.StatusInternalServerError, ErrInternalServerError), actual)
+	should.So(t, logger.lines, should.Equal, []string{"[WARN] unmapped error (*errors.errorString): not found"})
+}
+func TestErrorMap_Fallback(t *testing.T) {
+	failure := Error{ID: 54321, Name: "internal-server-error", Message: "Internal Server Error"}
+	mapper := NewErrorMap(Mapping.Fallback(http.StatusServiceUnavailable, failure))
+
+	actual := mapper.Response(errNotFound)
+
+	assertResponseEqual(t, Response.JSONErrors(http.StatusServiceUnavailable, failure), actual)
+}
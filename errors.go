@@ -9,19 +9,19 @@ type Error struct {
 	// Fields indicates the exact location(s) of the errors including the part of
 	// the HTTP request itself this is invalid. Valid field prefixes include
 	// "path", "query", "header", "form", and "body".
-	Fields []string `json:"fields,omitempty"`
+	Fields []string `json:"fields,omitempty" xml:"field,omitempty"`
 
 	// ID represents the unique, numeric contractual identifier that can be used to
 	// associate this error with a particular front-end error message, if any.
-	ID int `json:"id,omitempty"`
+	ID int `json:"id,omitempty" xml:"id,omitempty"`
 
 	// Name represents the unique string-based, contractual value that can be used to
 	// associate this error with a particular front-end error message, if any.
-	Name string `json:"name,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
 
 	// Message represents a friendly, user-facing message to indicate why there was a
 	// problem with the input.
-	Message string `json:"message,omitempty"`
+	Message string `json:"message,omitempty" xml:"message,omitempty"`
 }
 
 func (this Error) Error() string { return this.Message }
@@ -37,7 +37,7 @@ func (this Error) Is(err error) bool {
 
 // Errors represents a set of problems.
 type Errors struct {
-	Errors []Error `json:"errors,omitempty"`
+	Errors []Error `json:"errors,omitempty" xml:"error,omitempty"`
 }
 
 func NewErrors(values ...Error) *Errors {
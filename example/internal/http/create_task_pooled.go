@@ -1,13 +1,13 @@
 package http
 
 import (
-	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/smarty/scuter"
 	"github.com/smarty/scuter/example/internal/app"
+	"github.com/smarty/scuter/validation"
 )
 
 type (
@@ -25,61 +25,63 @@ type (
 	}
 )
 
+// Reset prepares the model for reuse (see scuter.Shell).
+func (this *CreateTaskModel) Reset() {
+	this.Request.DueDate = time.Time{}
+	this.Request.Details = ""
+	this.Command.Details = ""
+	this.Command.Result.Error = nil
+	this.Command.Result.ID = 0
+	this.Response.ID = 0
+	this.Response.Details = ""
+}
+
 // CreateTaskShell is intended to be a long-lived, concurrent-safe structure for serving all HTTP requests routed here.
 type CreateTaskShell struct {
-	pool    *scuter.Pool[*CreateTaskModel]
+	*scuter.Shell[*CreateTaskModel]
 	logger  app.Logger
 	handler app.Handler
+	errors  *scuter.ErrorMap
 }
 
 func NewCreateTaskShell(logger app.Logger, handler app.Handler) *CreateTaskShell {
-	return &CreateTaskShell{
-		pool:    scuter.NewPool(newCreateTaskModel),
+	this := &CreateTaskShell{
 		logger:  logger,
 		handler: handler,
+		errors: scuter.NewErrorMap(
+			scuter.Mapping.Is(app.ErrTaskTooHard, http.StatusTeapot, errTaskTooHard),
+			scuter.Mapping.Fallback(http.StatusInternalServerError, errInternalServerError),
+			scuter.Mapping.Logger(logger),
+		),
 	}
+	this.Shell = scuter.NewShell(nil, newCreateTaskModel, nil, this.serveHTTP)
+	return this
 }
 func newCreateTaskModel() *CreateTaskModel {
 	return &CreateTaskModel{Command: &app.CreateTaskCommand{}}
 }
-func resetCreateTaskModel(result *CreateTaskModel) {
-	result.Request.Details = ""
-	result.Command.Details = ""
-	result.Command.Result.Error = nil
-	result.Command.Result.ID = 0
-	result.Response.ID = 0
-	result.Response.Details = ""
-}
-func (this *CreateTaskShell) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-	model := this.pool.Get()
-	defer this.pool.Put(model)
-	resetCreateTaskModel(model)
-	scuter.Flush(response, this.serveHTTP(request, model))
-}
 func (this *CreateTaskShell) serveHTTP(request *http.Request, model *CreateTaskModel) (result scuter.ResponseOption) {
 	result, ok := scuter.ReadJSONRequestBody(request, &model.Request)
 	if !ok {
 		return result
 	}
-	if model.Request.DueDate.IsZero() {
-		result = scuter.Response.With(result, scuter.Response.JSONError(errMissingDueDate))
-	}
 	model.Request.Details = strings.TrimSpace(model.Request.Details)
-	if model.Request.Details == "" {
-		result = scuter.Response.With(result, scuter.Response.JSONError(errMissingDetails))
-	}
-	if result != nil {
-		return scuter.Response.With(result, scuter.Response.StatusCode(http.StatusUnprocessableEntity))
+	result, ok = validation.Validate(
+		validation.Field("due_date", model.Request.DueDate, validation.Required[time.Time]().Error(errMissingDueDate)),
+		validation.Field("details", model.Request.Details, validation.Required[string]().Error(errMissingDetails)),
+	)
+	if !ok {
+		return result
 	}
 
 	model.Command.Details = model.Request.Details
 	this.handler.Handle(request.Context(), model.Command)
 
 	switch {
-	case model.Command.Result.Error == nil && model.Command.Result.ID > 0:
+	case model.Command.Result.Error != nil:
+		return this.errors.Response(model.Command.Result.Error)
+	case model.Command.Result.ID > 0:
 		return this.ok(model)
-	case errors.Is(model.Command.Result.Error, app.ErrTaskTooHard):
-		return scuter.Response.JSONErrors(http.StatusTeapot, errTaskTooHard)
 	default:
 		return scuter.Response.JSONErrors(http.StatusInternalServerError, errInternalServerError)
 	}
@@ -39,7 +39,11 @@ func (this *CreateTaskFixture) TestInvalidJSONRequestBody() {
 			scuter.Request.Header("Content-Type", "application/json; charset=utf-8"),
 			scuter.Request.Body(strings.NewReader("invalid json")),
 		),
-		scuter.Response.JSONErrors(http.StatusBadRequest, scuter.ErrInvalidRequestJSONBody),
+		scuter.Response.JSONErrors(http.StatusBadRequest, scuter.Error{
+			Fields:  scuter.ErrInvalidRequestJSONBody.Fields,
+			Name:    scuter.ErrInvalidRequestJSONBody.Name,
+			Message: "The body was not well-formed JSON (invalid character 'i' looking for beginning of value at line 1, column 1).",
+		}),
 	)
 }
 func (this *CreateTaskFixture) TestInvalidFields() {
@@ -12,12 +12,17 @@ import (
 type DeleteTaskShell struct {
 	logger  app.Logger
 	handler app.Handler
+	errors  *scuter.ErrorMap
 }
 
 func NewDeleteTaskShell(logger app.Logger, handler app.Handler) *DeleteTaskShell {
 	return &DeleteTaskShell{
 		logger:  logger,
 		handler: handler,
+		errors: scuter.NewErrorMap(
+			scuter.Mapping.Fallback(http.StatusInternalServerError, errInternalServerError),
+			scuter.Mapping.Logger(logger),
+		),
 	}
 }
 
@@ -40,6 +45,6 @@ func (this *DeleteTaskShell) serveHTTP(request *http.Request) scuter.ResponseOpt
 	case errors.Is(command.Result.Error, app.ErrTaskNotFound):
 		return nil
 	default:
-		return scuter.Response.JSONErrors(http.StatusInternalServerError, errInternalServerError)
+		return this.errors.Response(command.Result.Error)
 	}
 }
@@ -0,0 +1,169 @@
+package scuter
+
+import (
+	"net/http"
+)
+
+// Flusher writes responses (see Flush) according to its own policy: the headers applied to every response, the
+// serialization of JSON and errors, and the observers notified of each response. Different services or route
+// groups within a single binary may each have their own Flusher. A Flusher is safe for concurrent use.
+type Flusher struct {
+	defaults     ResponseOption
+	errorFormat  ErrorFormat
+	observers    []FlushObserver
+	jsonOverride Codec // nil unless any of the JSON settings were supplied, see withJSON
+	compression  *compressionPolicy
+	configs      *Pool[*responseConfig]
+}
+
+// NewFlusher returns a Flusher configured with the supplied options, which may be supplied in any order.
+func NewFlusher(options ...FlusherOption) *Flusher {
+	this := &Flusher{}
+	this.configs = NewPool(func() *responseConfig {
+		config := &responseConfig{jsonErrors: NewErrors()}
+		config.reset(this, nil)
+		return config
+	})
+	for _, option := range options {
+		if option != nil {
+			option(this)
+		}
+	}
+	return this
+}
+
+// DefaultFlusher is the Flusher used by Flush, FlushErr, and Response.Static.
+var DefaultFlusher = NewFlusher()
+
+// Flush applies the options, which may be supplied in any order, to the provided ResponseWriter, after applying any
+// default headers of the Flusher (see Flush).
+func (this *Flusher) Flush(response http.ResponseWriter, options ...ResponseOption) {
+	_ = this.FlushErr(response, options...)
+}
+
+// FlushErr is like Flush, but also returns any errors that occur from IO operations involving the response body
+// (see FlushErr).
+func (this *Flusher) FlushErr(response http.ResponseWriter, options ...ResponseOption) error {
+	config := this.configs.Get()
+	defer this.configs.Put(config)
+	config.reset(this, response.Header())
+	if this.defaults != nil {
+		this.defaults(config)
+	}
+	for _, option := range options {
+		if option != nil {
+			option(config)
+		}
+	}
+	return config.flush(response)
+}
+
+// codec returns the JSON Codec established by the JSON settings of the Flusher in place of any other JSON codec.
+func (this *Flusher) codec(codec Codec) Codec {
+	if this.jsonOverride != nil && baseMediaType(codec.MediaType()) == "application/json" {
+		return this.jsonOverride
+	}
+	return codec
+}
+
+// jsonCodec returns the JSON Codec established by the JSON settings of the Flusher, if any, or else the Codec
+// registered in DefaultCodecs for JSON.
+func (this *Flusher) jsonCodec() Codec {
+	if this.jsonOverride != nil {
+		return this.jsonOverride
+	}
+	if codec, ok := DefaultCodecs.Lookup(jsonContentType); ok {
+		return codec
+	}
+	return JSONCodec{}
+}
+func (this *Flusher) jsonContentType() string {
+	if this.jsonOverride != nil {
+		return this.jsonOverride.MediaType()
+	}
+	return jsonContentType
+}
+func (this *Flusher) withJSON(configure func(*JSONCodec)) {
+	codec, _ := this.jsonOverride.(JSONCodec)
+	configure(&codec)
+	this.jsonOverride = codec
+}
+func (this *Flusher) observe(event FlushEvent) {
+	for _, observer := range this.observers {
+		observer(event)
+	}
+}
+
+// FlushObserver is notified of every response written by a Flusher, once the body (if any) has been written.
+type FlushObserver func(FlushEvent)
+
+// FlushEvent describes a response written by a Flusher.
+type FlushEvent struct {
+	// Request is the request being answered (see Response.Request and Response.Negotiate), if known.
+	Request *http.Request
+	// Status is the status code written.
+	Status int
+	// Header is the header written, which must not be modified.
+	Header http.Header
+	// Written is the number of bytes of the body written.
+	Written int64
+	// Err is the error returned by FlushErr, if any.
+	Err error
+}
+
+// FlusherOption is a callback func with an opportunity to modify the *Flusher being constructed.
+type FlusherOption func(*Flusher)
+
+// Flushing is the 'namespace' for all methods that return a FlusherOption.
+var Flushing flushingSingleton
+
+type flushingSingleton struct{}
+
+// Header adds the value associated with key to every response, before applying the options of the response itself.
+func (flushingSingleton) Header(key, value string) FlusherOption {
+	return func(this *Flusher) { this.defaults = Response.With(this.defaults, Response.Header(key, value)) }
+}
+
+// JSONIndent causes JSON to be indented (see json.Encoder.SetIndent).
+func (flushingSingleton) JSONIndent(prefix, indent string) FlusherOption {
+	return func(this *Flusher) {
+		this.withJSON(func(codec *JSONCodec) { codec.Prefix, codec.Indent = prefix, indent })
+	}
+}
+
+// JSONEscapeHTML determines whether problematic HTML characters are escaped within JSON strings (see
+// json.Encoder.SetEscapeHTML), which they are by default.
+func (flushingSingleton) JSONEscapeHTML(escape bool) FlusherOption {
+	return func(this *Flusher) {
+		this.withJSON(func(codec *JSONCodec) { codec.DisableHTMLEscaping = !escape })
+	}
+}
+
+// JSONMarshal replaces encoding/json for serializing JSON (see JSONCodec.Marshal).
+func (flushingSingleton) JSONMarshal(marshal func(any) ([]byte, error)) FlusherOption {
+	return func(this *Flusher) {
+		this.withJSON(func(codec *JSONCodec) { codec.Marshal = marshal })
+	}
+}
+
+// JSONContentType replaces the Content-Type header sent with JSON (ie. "application/json; charset=utf-8").
+func (flushingSingleton) JSONContentType(contentType string) FlusherOption {
+	return func(this *Flusher) {
+		this.withJSON(func(codec *JSONCodec) { codec.ContentType = contentType })
+	}
+}
+
+// ErrorFormat establishes how the errors supplied to Response.JSONError and Response.JSONErrors are serialized.
+// The default is ErrorsFormat.
+func (flushingSingleton) ErrorFormat(format ErrorFormat) FlusherOption {
+	return func(this *Flusher) { this.errorFormat = format }
+}
+
+// Observer registers the observer to be notified of every response written.
+func (flushingSingleton) Observer(observer FlushObserver) FlusherOption {
+	return func(this *Flusher) {
+		if observer != nil {
+			this.observers = append(this.observers, observer)
+		}
+	}
+}
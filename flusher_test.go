@@ -0,0 +1,112 @@
+package scuter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestFlusher_Headers(t *testing.T) {
+	flusher := NewFlusher(
+		Flushing.Header("X-Frame-Options", "DENY"),
+		Flushing.Header("Cache-Control", "no-store"),
+		nil,
+	)
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.StatusCode(http.StatusTeapot), Response.Header("Cache-Control", "private"))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusTeapot)
+	should.So(t, recorder.Header().Get("X-Frame-Options"), should.Equal, "DENY")
+	should.So(t, recorder.Header().Values("Cache-Control"), should.Equal, []string{"no-store", "private"})
+}
+func TestFlusher_JSONSettings(t *testing.T) {
+	flusher := NewFlusher(
+		Flushing.JSONIndent("", "  "),
+		Flushing.JSONEscapeHTML(false),
+		Flushing.JSONContentType("application/json"),
+	)
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.JSONBody(map[string]string{"html": "<b>"}))
+
+	should.So(t, recorder.Body.String(), should.Equal, "{\n  \"html\": \"<b>\"\n}\n")
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json")
+}
+func TestFlusher_JSONSettingsApplyToErrorsAndNegotiatedJSON(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONIndent(">", ""))
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Accept", "application/json"))
+	errorsRecorder, negotiatedRecorder := httptest.NewRecorder(), httptest.NewRecorder()
+
+	flusher.Flush(errorsRecorder, Response.JSONErrors(http.StatusBadRequest, Error{ID: 1}))
+	flusher.Flush(negotiatedRecorder, Response.Negotiated(request, []int{1}))
+
+	should.So(t, errorsRecorder.Body.String(), should.Equal, "{\n>\"errors\": [\n>{\n>\"id\": 1\n>}\n>]\n>}\n")
+	should.So(t, negotiatedRecorder.Body.String(), should.Equal, "[\n>1\n>]\n")
+	should.So(t, negotiatedRecorder.Header().Get("Content-Type"), should.Equal, jsonContentType)
+}
+func TestFlusher_JSONMarshal(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONMarshal(func(v any) ([]byte, error) {
+		if _, ok := v.(int); ok {
+			return []byte("custom"), nil
+		}
+		return nil, errors.New("unsupported")
+	}))
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.JSONBody(42))
+
+	should.So(t, recorder.Body.String(), should.Equal, "custom\n")
+}
+func TestFlusher_JSONMarshalFailure(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONMarshal(func(v any) ([]byte, error) { return nil, errors.New("failure") }))
+	recorder := httptest.NewRecorder()
+
+	err := flusher.FlushErr(recorder, Response.JSONBody(42))
+
+	should.So(t, errors.Is(err, ErrResponseEncode), should.BeTrue)
+	should.So(t, recorder.Code, should.Equal, http.StatusInternalServerError)
+	should.So(t, recorder.Body.String(), should.Equal, "")
+}
+func TestFlusher_ErrorFormat(t *testing.T) {
+	flusher := NewFlusher(Flushing.ErrorFormat(ProblemFormat))
+	problemRecorder, defaultRecorder := httptest.NewRecorder(), httptest.NewRecorder()
+
+	flusher.Flush(problemRecorder, Response.JSONErrors(http.StatusBadRequest, Error{Message: "message"}))
+	Flush(defaultRecorder, Response.JSONErrors(http.StatusBadRequest, Error{Message: "message"}))
+
+	should.So(t, problemRecorder.Header().Get("Content-Type"), should.Equal, "application/problem+json")
+	should.So(t, defaultRecorder.Header().Get("Content-Type"), should.Equal, jsonContentType)
+}
+func TestFlusher_Observer(t *testing.T) {
+	var events []FlushEvent
+	observer := func(event FlushEvent) { events = append(events, event) }
+	flusher := NewFlusher(Flushing.Observer(observer), Flushing.Observer(nil))
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.Request(request), Response.StatusCode(http.StatusCreated), Response.JSONBody(1))
+	err := flusher.FlushErr(httptest.NewRecorder(), Response.JSONBody(make(chan int)))
+
+	should.So(t, len(events), should.Equal, 2)
+	should.So(t, events[0].Request, should.Equal, request)
+	should.So(t, events[0].Status, should.Equal, http.StatusCreated)
+	should.So(t, events[0].Header.Get("Content-Length"), should.Equal, "2")
+	should.So(t, events[0].Written, should.Equal, int64(2))
+	should.So(t, events[0].Err, should.BeNil)
+	should.So(t, events[1].Status, should.Equal, http.StatusInternalServerError)
+	should.So(t, events[1].Err, should.Equal, err)
+}
+func TestFlush_DefaultFlusher(t *testing.T) {
+	defer func(original *Flusher) { DefaultFlusher = original }(DefaultFlusher)
+	DefaultFlusher = NewFlusher(Flushing.Header("X-Default", "value"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder)
+
+	should.So(t, recorder.Header().Get("X-Default"), should.Equal, "value")
+}
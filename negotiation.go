@@ -0,0 +1,100 @@
+package scuter
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotAcceptable = Error{
+		Fields:  []string{"header.Accept"},
+		Name:    "not-acceptable",
+		Message: "None of the media types listed in the Accept header are supported.",
+	}
+)
+
+// Negotiate returns the registered codec most acceptable according to the provided Accept header values, which are
+// parsed per RFC 9110 (quality values, wildcards, and parameters). The most specific media range matching a codec
+// determines its quality, and ties are broken in favor of the codec registered first. An absent (or empty) Accept
+// header accepts anything. The result is false if no codec is acceptable.
+func (this *Codecs) Negotiate(accept ...string) (Codec, bool) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	var best Codec
+	bestQuality := 0.0
+	for _, entry := range this.entries {
+		if quality := entry.acceptQuality(accept); quality > bestQuality {
+			best, bestQuality = entry.codec, quality
+		}
+	}
+	return best, best != nil
+}
+
+// acceptQuality returns the quality value assigned to the entry by the most specific matching media range.
+func (this codecEntry) acceptQuality(accept []string) float64 {
+	quality, specificity, empty := 0.0, -1, true
+	for _, header := range accept {
+		for mediaRange := range strings.SplitSeq(header, ",") {
+			if strings.TrimSpace(mediaRange) == "" {
+				continue
+			}
+			empty = false
+			if rangeQuality, rangeSpecificity, ok := this.match(mediaRange); ok && rangeSpecificity > specificity {
+				quality, specificity = rangeQuality, rangeSpecificity
+			}
+		}
+	}
+	if empty {
+		return 1
+	}
+	return quality
+}
+
+// match reports whether the media range (ie. "text/*;q=0.5") matches the entry, along with its quality value and
+// specificity ("*/*" < "type/*" < "type/subtype" < "type/subtype;param=value").
+func (this codecEntry) match(mediaRange string) (quality float64, specificity int, ok bool) {
+	mediaType, parameters, _ := strings.Cut(mediaRange, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	mainType, _, _ := strings.Cut(this.mediaType, "/")
+	switch {
+	case mediaType == "*/*":
+		specificity = 0
+	case strings.HasSuffix(mediaType, "/*") && strings.TrimSuffix(mediaType, "/*") == mainType:
+		specificity = 1
+	case mediaType == this.mediaType:
+		specificity = 2
+	default:
+		return 0, 0, false
+	}
+	quality = 1
+	for parameter := range strings.SplitSeq(parameters, ";") {
+		key, value, _ := strings.Cut(parameter, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.Trim(strings.TrimSpace(value), `"`)
+		if key == "" {
+			continue
+		}
+		if key == "q" {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = min(max(parsed, 0), 1)
+			}
+			break // any parameters following the weight are extensions, not media type parameters
+		}
+		if !strings.EqualFold(codecParameter(this.codec.MediaType(), key), value) {
+			return 0, 0, false
+		}
+		specificity++
+	}
+	return quality, specificity, true
+}
+
+// codecParameter returns the value of the named parameter of the Content-Type value.
+func codecParameter(contentType, name string) string {
+	_, parameters, _ := strings.Cut(contentType, ";")
+	for parameter := range strings.SplitSeq(parameters, ";") {
+		key, value, _ := strings.Cut(parameter, "=")
+		if strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package scuter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestCodecsNegotiate(t *testing.T) {
+	codecs := NewCodecs(JSONCodec{}, XMLCodec{})
+
+	assertNegotiated(t, codecs, nil, JSONCodec{})
+	assertNegotiated(t, codecs, []string{""}, JSONCodec{})
+	assertNegotiated(t, codecs, []string{"*/*"}, JSONCodec{})
+	assertNegotiated(t, codecs, []string{"application/xml"}, XMLCodec{})
+	assertNegotiated(t, codecs, []string{"Application/XML"}, XMLCodec{})
+	assertNegotiated(t, codecs, []string{"application/*"}, JSONCodec{})
+	assertNegotiated(t, codecs, []string{"application/json;q=0.5, application/xml"}, XMLCodec{})
+	assertNegotiated(t, codecs, []string{"application/json;q=0.5", "application/xml;q=0.9"}, XMLCodec{})
+	assertNegotiated(t, codecs, []string{"*/*;q=0.1, application/json;q=0"}, XMLCodec{})
+	assertNegotiated(t, codecs, []string{"application/*;q=0.2, application/xml;q=0.1"}, JSONCodec{})
+	assertNegotiated(t, codecs, []string{"application/json; charset=UTF-8"}, JSONCodec{})
+	assertNegotiated(t, codecs, []string{`application/json; charset="utf-8"; q=0.8; ext=1, */*; q=0.1`}, JSONCodec{})
+	assertNegotiated(t, codecs, []string{"application/json; charset=latin1, application/xml;q=0.1"}, XMLCodec{})
+	assertNegotiated(t, codecs, []string{"text/html"}, nil)
+	assertNegotiated(t, codecs, []string{"application/json;q=0"}, nil)
+}
+func assertNegotiated(t *testing.T, codecs *Codecs, accept []string, expected Codec) {
+	t.Helper()
+	codec, ok := codecs.Negotiate(accept...)
+	should.So(t, ok, should.Equal, expected != nil)
+	should.So(t, codec, should.Equal, expected)
+}
+
+func TestResponseNegotiated(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "application/xml, application/json;q=0.9"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiated(request, codecModel{Name: "Gopher"}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/xml; charset=utf-8")
+	should.So(t, recorder.Header().Get("Vary"), should.Equal, "Accept")
+	should.So(t, recorder.Body.String(), should.Equal, `<codecModel><name>Gopher</name></codecModel>`)
+}
+func TestResponseNegotiated_NoAcceptHeader(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/")
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Header("Vary", "Origin, accept"), Response.Negotiated(request, codecModel{Name: "Gopher"}))
+
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Header().Values("Vary"), should.Equal, []string{"Origin, accept"})
+	should.So(t, recorder.Body.String(), should.Equal, `{"name":"Gopher"}`+"\n")
+}
+func TestResponseNegotiated_NotAcceptable(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "text/html"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiated(request, codecModel{Name: "Gopher"}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusNotAcceptable)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal,
+		`{"errors":[{"fields":["header.Accept"],"name":"not-acceptable","message":"None of the media types listed in the Accept header are supported."}]}`+"\n")
+}
+func TestResponseNegotiate_Errors(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "application/xml"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiate(request), Response.JSONErrors(http.StatusBadRequest, Error{
+		Fields: []string{"query.page"}, ID: 1, Name: "bad-page", Message: "bad page",
+	}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusBadRequest)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/xml; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal,
+		`<Errors><error><field>query.page</field><id>1</id><name>bad-page</name><message>bad page</message></error></Errors>`)
+}
+func TestResponseNegotiate_ErrorsNotAcceptable(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "text/html"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiate(request), Response.JSONErrors(http.StatusBadRequest, ErrInternalServerError))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusBadRequest)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal,
+		`{"errors":[{"name":"internal-server-error","message":"Internal Server Error"}]}`+"\n")
+}
+func TestResponseNegotiate_NoBody(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "text/html"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiate(request), Response.StatusCode(http.StatusNoContent))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusNoContent)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "")
+}
@@ -0,0 +1,129 @@
+package scuter
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// Problem represents the "problem details" of an error response as defined by RFC 9457, with the Errors extension
+// member carrying the same Error values that would otherwise be found in the Errors envelope.
+type Problem struct {
+	XMLName xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+
+	// Type is a URI reference identifying the problem type, which defaults to "about:blank".
+	Type string `json:"type,omitempty" xml:"type,omitempty"`
+
+	// Title is a short, human-readable summary of the problem type, which defaults to the text of the Status.
+	Title string `json:"title,omitempty" xml:"title,omitempty"`
+
+	// Status is the HTTP status code of the response.
+	Status int `json:"status,omitempty" xml:"status,omitempty"`
+
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty" xml:"detail,omitempty"`
+
+	// Instance is a URI reference identifying this specific occurrence of the problem.
+	Instance string `json:"instance,omitempty" xml:"instance,omitempty"`
+
+	// Errors is an extension member listing the individual problems, most likely with the calling HTTP request.
+	Errors []Error `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// MarshalXML serializes the problem according to its struct tags, except that the errors element is omitted
+// altogether when there are no Errors (rather than left empty, as the "errors>error,omitempty" tag would have it).
+func (this Problem) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	problem := xmlProblem{
+		Type:     this.Type,
+		Title:    this.Title,
+		Status:   this.Status,
+		Detail:   this.Detail,
+		Instance: this.Instance,
+	}
+	if len(this.Errors) > 0 {
+		problem.Errors = &xmlProblemErrors{Errors: this.Errors}
+	}
+	if start.Name == (xml.Name{Local: "Problem"}) { // named after the type, as encoding/xml does for a Marshaler
+		start.Name = xml.Name{Space: problemXMLNamespace, Local: "problem"}
+	}
+	return encoder.EncodeElement(problem, start)
+}
+
+type xmlProblem struct {
+	Type     string            `xml:"type,omitempty"`
+	Title    string            `xml:"title,omitempty"`
+	Status   int               `xml:"status,omitempty"`
+	Detail   string            `xml:"detail,omitempty"`
+	Instance string            `xml:"instance,omitempty"`
+	Errors   *xmlProblemErrors `xml:"errors,omitempty"`
+}
+type xmlProblemErrors struct {
+	Errors []Error `xml:"error"`
+}
+
+// ErrorFormat determines how the errors supplied to Response.JSONError and Response.JSONErrors are serialized.
+type ErrorFormat int
+
+const (
+	// ErrorsFormat serializes errors within the Errors envelope (ie. `{"errors":[...]}`).
+	ErrorsFormat ErrorFormat = iota
+
+	// ProblemFormat serializes errors within a Problem (ie. application/problem+json) whose Status and Title
+	// reflect the status code of the response, and whose Detail is the Message of a lone error.
+	ProblemFormat
+)
+
+// SetErrorFormat establishes how the errors supplied to Response.JSONError and Response.JSONErrors are serialized
+// by Flush (see DefaultFlusher and Flushing.ErrorFormat). The default is ErrorsFormat.
+// IMPORTANT: this function is not safe to call concurrently with Flush; call it during initialization.
+func SetErrorFormat(format ErrorFormat) { DefaultFlusher.errorFormat = format }
+
+// Problem sets the status code (when problem.Status is non-zero) and serializes the problem to the ResponseWriter as
+// application/problem+json (or application/problem+xml when negotiated, see Response.Negotiate). The Status always
+// reflects the status code of the response as flushed, even when set by a later option (see Response.StatusCode),
+// and the Type and Title, when empty, default to "about:blank" and the text of that status code, respectively.
+func (responseSingleton) Problem(problem Problem) ResponseOption {
+	return func(config *responseConfig) {
+		if problem.Status != 0 {
+			config.status = problem.Status
+		}
+		setHeader(config.header, headerContentType, problemJSONContentType)
+		config.dataValue = problem // completed with the final status by encodeBody
+		config.problem = true
+	}
+}
+
+// newErrorsProblem returns the Problem describing the errors, as rendered with ProblemFormat.
+func newErrorsProblem(status int, errs []Error) Problem {
+	problem := Problem{Errors: errs}
+	if len(errs) == 1 {
+		problem.Detail = errs[0].Message
+	}
+	return completeProblem(problem, status)
+}
+func completeProblem(problem Problem, status int) Problem {
+	problem.Status = status
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(status)
+	}
+	return problem
+}
+
+// problemContentType returns the Content-Type of a problem serialized with the codec.
+func problemContentType(codec Codec) string {
+	switch baseMediaType(codec.MediaType()) {
+	case "application/json":
+		return problemJSONContentType
+	case "application/xml":
+		return problemXMLContentType
+	}
+	return codec.MediaType()
+}
+
+const (
+	problemJSONContentType = "application/problem+json"
+	problemXMLContentType  = "application/problem+xml"
+	problemXMLNamespace    = "urn:ietf:rfc:7807"
+)
@@ -0,0 +1,114 @@
+package scuter
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestResponseProblem(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Problem(Problem{
+		Type:     "https://example.com/probs/out-of-credit",
+		Title:    "You do not have enough credit.",
+		Status:   http.StatusForbidden,
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+		Errors:   []Error{{Fields: []string{"body.amount"}, Name: "insufficient-credit"}},
+	}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusForbidden)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/problem+json")
+	should.So(t, recorder.Body.String(), should.Equal, `{`+
+		`"type":"https://example.com/probs/out-of-credit",`+
+		`"title":"You do not have enough credit.",`+
+		`"status":403,`+
+		`"detail":"Your current balance is 30, but that costs 50.",`+
+		`"instance":"/account/12345/msgs/abc",`+
+		`"errors":[{"fields":["body.amount"],"name":"insufficient-credit"}]}`+"\n")
+}
+func TestResponseProblem_Defaults(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.StatusCode(http.StatusConflict), Response.Problem(Problem{}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusConflict)
+	should.So(t, recorder.Body.String(), should.Equal, `{"type":"about:blank","title":"Conflict","status":409}`+"\n")
+}
+func TestResponseProblem_LaterStatusCode(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Problem(Problem{Status: http.StatusForbidden}), Response.StatusCode(http.StatusConflict))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusConflict)
+	should.So(t, recorder.Body.String(), should.Equal, `{"type":"about:blank","title":"Conflict","status":409}`+"\n")
+}
+func TestResponseProblem_NegotiatedXML(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "application/xml"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiate(request), Response.Problem(Problem{
+		Status: http.StatusNotFound,
+		Errors: []Error{{Name: "not-found"}},
+	}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusNotFound)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/problem+xml")
+	should.So(t, recorder.Body.String(), should.Equal, `<problem xmlns="urn:ietf:rfc:7807">`+
+		`<type>about:blank</type><title>Not Found</title><status>404</status>`+
+		`<errors><error><name>not-found</name></error></errors></problem>`)
+}
+func TestResponseProblem_NegotiatedXMLWithoutErrors(t *testing.T) {
+	request := NewTestRequest(t.Context(), "GET", "/", Request.Header("Accept", "application/xml"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Negotiate(request), Response.Problem(Problem{Status: http.StatusConflict}))
+
+	should.So(t, recorder.Body.String(), should.Equal, `<problem xmlns="urn:ietf:rfc:7807">`+
+		`<type>about:blank</type><title>Conflict</title><status>409</status></problem>`)
+	var decoded Problem
+	should.So(t, xml.Unmarshal(recorder.Body.Bytes(), &decoded), should.BeNil)
+	should.So(t, decoded.Status, should.Equal, http.StatusConflict)
+	should.So(t, decoded.Errors, should.BeNil)
+}
+func TestResponseProblem_ErrorsTakePrecedence(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.JSONErrors(http.StatusInternalServerError, ErrInternalServerError),
+		Response.Problem(Problem{Title: "ignored"}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusInternalServerError)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal,
+		`{"errors":[{"name":"internal-server-error","message":"Internal Server Error"}]}`+"\n")
+}
+func TestProblemFormat(t *testing.T) {
+	defer SetErrorFormat(ErrorsFormat)
+	SetErrorFormat(ProblemFormat)
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.JSONErrors(http.StatusUnprocessableEntity,
+		Error{Fields: []string{"body.name"}, Name: "missing-name", Message: "The name is required."},
+		Error{Fields: []string{"body.age"}, Name: "missing-age", Message: "The age is required."},
+	))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusUnprocessableEntity)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/problem+json")
+	should.So(t, recorder.Body.String(), should.Equal, `{"type":"about:blank","title":"Unprocessable Entity","status":422,"errors":[`+
+		`{"fields":["body.name"],"name":"missing-name","message":"The name is required."},`+
+		`{"fields":["body.age"],"name":"missing-age","message":"The age is required."}]}`+"\n")
+}
+func TestProblemFormat_SingleErrorDetail(t *testing.T) {
+	defer SetErrorFormat(ErrorsFormat)
+	SetErrorFormat(ProblemFormat)
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.JSONErrors(http.StatusInternalServerError, ErrInternalServerError))
+
+	should.So(t, recorder.Body.String(), should.Equal, `{"type":"about:blank","title":"Internal Server Error","status":500,`+
+		`"detail":"Internal Server Error","errors":[{"name":"internal-server-error","message":"Internal Server Error"}]}`+"\n")
+}
@@ -0,0 +1,5 @@
+//go:build race
+
+package scuter
+
+func init() { raceEnabled = true }
@@ -1,7 +1,8 @@
 package scuter
 
 import (
-	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -19,20 +20,72 @@ var (
 		Name:    "malformed-request-payload",
 		Message: "The body did not contain well-formed data and could not be properly deserialized.",
 	}
+	ErrInvalidRequestBody = Error{
+		Fields:  []string{"body"},
+		Name:    "malformed-request-body",
+		Message: "The body did not contain well-formed data and could not be properly deserialized.",
+	}
+	ErrRequestBodyTooLarge = Error{
+		Fields:  []string{"body"},
+		Name:    "request-body-too-large",
+		Message: "The body exceeded the maximum allowed size.",
+	}
 )
 
 // ReadJSONRequestBody ensures the Content-Type header indicates JSON and if so, proceeds to unmarshal the body into
 // the provided value. Failure at any point results in a JSON error which can be sent to the client with Flush.
-func ReadJSONRequestBody(request *http.Request, v any) (ResponseOption, bool) {
+// Malformed content results in a copy of ErrInvalidRequestJSONBody whose Fields and Message pinpoint the problem,
+// such that it should be recognized by its Name rather than compared with ErrInvalidRequestJSONBody as a whole.
+// Bodies compressed with gzip or deflate (see the Content-Encoding header) are decompressed transparently, whereas
+// other encodings result in ErrUnsupportedRequestContentEncoding with 415 Unsupported Media Type. The options, if
+// any, are applied after those supplied to SetDefaultReadOptions.
+func ReadJSONRequestBody(request *http.Request, v any, options ...ReadOption) (ResponseOption, bool) {
+	config := newReadConfig(options)
 	if !isJSONContent(request) {
 		return Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), false
 	}
-	if err := json.NewDecoder(request.Body).Decode(&v); err != nil { // FUTURE: upgrade to json/v2's json.UnmarshalRead
-		return Response.JSONErrors(http.StatusBadRequest, ErrInvalidRequestJSONBody), false
+	if result, ok := config.prepareBody(request); !ok {
+		return result, false
+	}
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		return readBodyError(err, ErrInvalidRequestJSONBody), false
+	}
+	return decodeJSONRequestBody(data, v, config.strict)
+}
+
+// ReadRequestBody looks up the Codec registered in DefaultCodecs for the Content-Type header and if found, proceeds
+// to decode the body into the provided value (or has the codec do so, see RequestBodyDecoder, such that JSON bodies
+// are read as with ReadJSONRequestBody). Failure at any point results in a JSON error which can be sent to the client
+// with Flush, where content which the codec can't decode results in ErrInvalidRequestBody. The options, if any, are
+// applied after those supplied to SetDefaultReadOptions.
+func ReadRequestBody(request *http.Request, v any, options ...ReadOption) (ResponseOption, bool) {
+	codec, ok := DefaultCodecs.Lookup(request.Header.Get(headerContentType))
+	if !ok {
+		return Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), false
+	}
+	if decoder, ok := codec.(RequestBodyDecoder); ok {
+		return decoder.DecodeRequestBody(request, v, options...)
+	}
+	config := newReadConfig(options)
+	if result, ok := config.prepareBody(request); !ok {
+		return result, false
+	}
+	if err := codec.Decode(request.Body, v); err != nil {
+		return readBodyError(err, ErrInvalidRequestBody), false
 	}
 	return nil, true
 }
 
+// readBodyError translates an error encountered while reading the request body into a JSON error response,
+// falling back to the provided error when the problem was with the content rather than its size.
+func readBodyError(err error, fallback Error) ResponseOption {
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		return Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge)
+	}
+	return Response.JSONErrors(http.StatusBadRequest, fallback)
+}
+
 func isJSONContent(request *http.Request) bool {
 	for _, contentType := range request.Header[headerContentType] {
 		if strings.Contains(contentType, "json") {
@@ -42,7 +95,8 @@ func isJSONContent(request *http.Request) bool {
 	return false
 }
 
-// ReadUint64Header parses the first header value corresponding with key as a uint64.
+// ReadUint64Header parses the first header value corresponding with key as a uint64, else returns 0.
+// See RequestReader for an alternative which reports malformed values rather than ignoring them.
 func ReadUint64Header(headers http.Header, key string) uint64 {
 	if values, contains := headers[key]; contains && len(values) > 0 {
 		parsed, _ := strconv.ParseUint(values[0], 10, 64)
@@ -80,3 +134,114 @@ func ReadNumericPathElement(rawPath, label string) uint64 {
 	value, _ := strconv.ParseUint(raw, 10, 64)
 	return value
 }
+
+// ReadOption is a callback func with an opportunity to modify the *readConfig.
+type ReadOption func(*readConfig)
+
+// Read is the 'namespace' for all methods that return a ReadOption.
+var Read readSingleton
+
+type readSingleton struct{}
+
+// With returns a 'composite' option which will be the result of calling all options in the provided order.
+func (readSingleton) With(options ...ReadOption) ReadOption {
+	return func(config *readConfig) {
+		for _, option := range options {
+			if option != nil {
+				option(config)
+			}
+		}
+	}
+}
+
+// MaxBytes limits the size of the request body to n bytes (a value <= 0 means no limit). Larger bodies, whether or
+// not they declare a Content-Length, result in ErrRequestBodyTooLarge with 413 Request Entity Too Large. The limit
+// applies to the decompressed body of a request with a Content-Encoding, which protects against decompression bombs.
+func (readSingleton) MaxBytes(n int64) ReadOption {
+	return func(config *readConfig) { config.maxBytes, config.explicitMaxBytes = n, true }
+}
+
+// Strict rejects JSON bodies containing unknown fields, duplicate object keys, or data beyond the first value, each
+// of which is reported as its own error (ErrUnknownRequestField, ErrDuplicateRequestField, ErrTrailingRequestData)
+// with 400 Bad Request. Fields identify the offending JSON path (ie. "body.items[2].name").
+func (readSingleton) Strict(strict bool) ReadOption {
+	return func(config *readConfig) { config.strict = strict }
+}
+
+// MaxFileBytes limits the size of each file uploaded in a multipart/form-data body to n bytes (a value <= 0 means
+// no limit other than that of MaxBytes). Larger files result in ErrRequestFileTooLarge with 413 Request Entity Too
+// Large.
+func (readSingleton) MaxFileBytes(n int64) ReadOption {
+	return func(config *readConfig) { config.maxFileBytes = n }
+}
+
+// AllowedFileTypes restricts the media types of files uploaded in a multipart/form-data body (as detected from their
+// content, see FileUpload.ContentType) to those provided, each of which may be a wildcard (ie. "image/*"). Other
+// files result in ErrUnsupportedRequestFileType with 415 Unsupported Media Type. Without this option, files of any
+// type are allowed.
+func (readSingleton) AllowedFileTypes(mediaTypes ...string) ReadOption {
+	return func(config *readConfig) { config.allowedFileTypes = mediaTypes }
+}
+
+// MaxLineBytes limits the size of each line of a newline-delimited JSON body to n bytes (a value <= 0 means no limit
+// other than that of an explicit MaxBytes). Larger lines are reported as ErrRequestLineTooLarge (see
+// ReadNDJSONRequestBody).
+func (readSingleton) MaxLineBytes(n int) ReadOption {
+	return func(config *readConfig) { config.maxLineBytes = n }
+}
+
+// SetDefaultReadOptions restores the built-in defaults and then applies the provided options to them, establishing
+// the configuration which each ReadXxxRequestBody function starts from before applying any per-call options.
+// IMPORTANT: this function is not safe to call concurrently with request handling; call it during initialization.
+func SetDefaultReadOptions(options ...ReadOption) {
+	readDefaults = builtinReadDefaults
+	Read.With(options...)(&readDefaults)
+}
+
+type readConfig struct {
+	maxBytes         int64
+	explicitMaxBytes bool // whether maxBytes was established by MaxBytes, rather than by default
+	strict           bool
+	maxFileBytes     int64
+	allowedFileTypes []string
+	maxLineBytes     int
+}
+
+func newReadConfig(options []ReadOption) readConfig {
+	config := readDefaults
+	for _, option := range options {
+		if option != nil {
+			option(&config)
+		}
+	}
+	return config
+}
+
+// prepareBody decompresses the body according to its Content-Encoding (see decodeBody), then rejects requests whose
+// declared Content-Length exceeds the limit and wraps the body so that reading past the limit fails (which covers
+// chunked uploads that declare no Content-Length, as well as decompressed bodies, whatever their compressed size).
+func (this readConfig) prepareBody(request *http.Request) (ResponseOption, bool) {
+	if result, ok := decodeBody(request); !ok {
+		return result, false
+	}
+	if this.maxBytes <= 0 {
+		return nil, true
+	}
+	if request.ContentLength > this.maxBytes {
+		return Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), false
+	}
+	request.Body = http.MaxBytesReader(nil, request.Body, this.maxBytes)
+	return nil, true
+}
+
+var (
+	builtinReadDefaults = readConfig{maxBytes: defaultMaxRequestBodyBytes, maxLineBytes: defaultMaxRequestLineBytes}
+	readDefaults        = builtinReadDefaults
+)
+
+// defaultMaxRequestBodyBytes matches the limit imposed by http.Request.ParseForm on url-encoded bodies. It doesn't
+// apply to streamed bodies (see ReadNDJSONRequestBody), whose records are limited individually instead.
+const defaultMaxRequestBodyBytes = 10 << 20
+
+// defaultMaxRequestLineBytes limits each record of a newline-delimited JSON body (see Read.MaxLineBytes).
+const defaultMaxRequestLineBytes = 1 << 20
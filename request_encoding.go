@@ -0,0 +1,68 @@
+package scuter
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var ErrUnsupportedRequestContentEncoding = Error{
+	Fields:  []string{"header.Content-Encoding"},
+	Name:    "unsupported-content-encoding",
+	Message: "The content-encoding was not supported.",
+}
+
+// decodeBody replaces a body compressed with gzip or deflate (see the Content-Encoding header) with one which
+// decompresses it, removing the Content-Encoding and Content-Length headers (which describe the compressed body),
+// and rejects bodies with any other encoding with ErrUnsupportedRequestContentEncoding and 415 Unsupported Media Type.
+func decodeBody(request *http.Request) (ResponseOption, bool) {
+	values := request.Header.Values(headerContentEncoding)
+	if len(values) == 0 {
+		return nil, true
+	}
+	encoding := strings.ToLower(strings.TrimSpace(strings.Join(values, ",")))
+	switch encoding {
+	case "", "identity":
+	case encodingGzip, "x-gzip", encodingDeflate:
+		request.Body = &decodingReader{body: request.Body, deflate: encoding == encodingDeflate}
+		request.ContentLength = -1
+		request.Header.Del(headerContentLength)
+	default: // including combinations of encodings (ie. "gzip, br")
+		return Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentEncoding), false
+	}
+	request.Header.Del(headerContentEncoding)
+	return nil, true
+}
+
+// decodingReader decompresses the body, deferring the reading of its header until the body is first read, so that
+// a malformed body is reported like any other (ie. as ErrInvalidRequestJSONBody or ErrInvalidRequestBody).
+type decodingReader struct {
+	body    io.ReadCloser
+	deflate bool
+	reader  io.ReadCloser
+	err     error
+}
+
+func (this *decodingReader) Read(p []byte) (int, error) {
+	if this.reader == nil && this.err == nil {
+		if this.deflate {
+			this.reader, this.err = zlib.NewReader(this.body)
+		} else {
+			this.reader, this.err = gzip.NewReader(this.body)
+		}
+	}
+	if this.err != nil {
+		return 0, this.err
+	}
+	return this.reader.Read(p)
+}
+func (this *decodingReader) Close() error {
+	var err error
+	if this.reader != nil {
+		err = this.reader.Close()
+	}
+	return errors.Join(err, this.body.Close())
+}
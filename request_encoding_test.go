@@ -0,0 +1,119 @@
+package scuter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func compress(t *testing.T, encoding, content string) io.Reader {
+	t.Helper()
+	buffer := new(bytes.Buffer)
+	var writer io.WriteCloser = gzip.NewWriter(buffer)
+	if encoding == "deflate" {
+		writer = zlib.NewWriter(buffer)
+	}
+	_, err := io.WriteString(writer, content)
+	should.So(t, err, should.BeNil)
+	should.So(t, writer.Close(), should.BeNil)
+	return buffer
+}
+
+func TestReadJSONRequestBody_ContentEncoding(t *testing.T) {
+	for _, encoding := range []string{"gzip", "x-gzip", "deflate", "GZIP"} {
+		compressed := compress(t, strings.ToLower(strings.TrimPrefix(encoding, "x-")), `{"a":"1234"}`)
+		request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+			Request.Header("Content-Type", "application/json"),
+			Request.Header("Content-Encoding", encoding),
+			Request.Body(compressed),
+		))
+		v := make(map[string]any)
+
+		actual, ok := ReadJSONRequestBody(request, &v)
+
+		should.So(t, ok, should.BeTrue)
+		should.So(t, actual, should.BeNil)
+		should.So(t, v["a"], should.Equal, "1234")
+		should.So(t, request.Header.Get("Content-Encoding"), should.Equal, "")
+	}
+}
+func TestReadJSONRequestBody_IdentityContentEncoding(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Header("Content-Encoding", "identity"),
+		Request.Body(strings.NewReader(`{"a":"1234"}`)),
+	))
+	v := make(map[string]any)
+
+	_, ok := ReadJSONRequestBody(request, &v)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, v["a"], should.Equal, "1234")
+}
+func TestReadJSONRequestBody_UnsupportedContentEncoding(t *testing.T) {
+	for _, encoding := range []string{"br", "gzip, br", "compress"} {
+		request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+			Request.Header("Content-Type", "application/json"),
+			Request.Header("Content-Encoding", encoding),
+			Request.Body(strings.NewReader(`{}`)),
+		))
+
+		actual, ok := ReadJSONRequestBody(request, &map[string]any{})
+
+		should.So(t, ok, should.BeFalse)
+		assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType, Error{
+			Fields:  []string{"header.Content-Encoding"},
+			Name:    "unsupported-content-encoding",
+			Message: "The content-encoding was not supported.",
+		}), actual)
+	}
+}
+func TestReadJSONRequestBody_MalformedContentEncoding(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Header("Content-Encoding", "gzip"),
+		Request.Body(strings.NewReader(`{"a":"1234"}`)),
+	))
+
+	actual, ok := ReadJSONRequestBody(request, &map[string]any{})
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, ErrInvalidRequestJSONBody), actual)
+}
+func TestReadJSONRequestBody_DecompressedBodyBeyondLimit(t *testing.T) {
+	content := `{"a":"` + strings.Repeat("x", 10_000) + `"}`
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Header("Content-Encoding", "gzip"),
+		Request.Body(compress(t, "gzip", content)),
+	))
+	should.So(t, request.ContentLength < 1000, should.BeTrue)
+
+	actual, ok := ReadJSONRequestBody(request, &map[string]any{}, Read.MaxBytes(1000))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadNDJSONRequestBody_ContentEncoding(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Header("Content-Encoding", "gzip"),
+		Request.Body(compress(t, "gzip", "{\"id\":1}\n{\"id\":2}\n")),
+	))
+
+	records, _, ok := ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	should.So(t, ok, should.BeTrue)
+	var ids []int
+	for record, err := range records {
+		should.So(t, err, should.BeNil)
+		ids = append(ids, record.ID)
+	}
+	should.So(t, ids, should.Equal, []int{1, 2})
+}
@@ -0,0 +1,214 @@
+package scuter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrRequestFileTooLarge = Error{
+		Name:    "request-file-too-large",
+		Message: "The file exceeded the maximum allowed size.",
+	}
+	ErrUnsupportedRequestFileType = Error{
+		Name:    "unsupported-file-type",
+		Message: "The type of the file was not supported.",
+	}
+)
+
+// ReadFormRequestBody ensures the Content-Type header indicates a url-encoded form and if so, proceeds to parse the
+// body and populate the fields of v tagged with `form:"name"` (see Bind). A nil v results in the form being parsed
+// (see http.Request.PostForm) but not bound. Failure at any point results in a JSON error which can be sent to the
+// client with Flush. The options, if any, are applied after those supplied to SetDefaultReadOptions.
+func ReadFormRequestBody(request *http.Request, v any, options ...ReadOption) (ResponseOption, bool) {
+	config := newReadConfig(options)
+	if !hasMediaType(request, formContentType) {
+		return Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), false
+	}
+	if result, ok := config.prepareBody(request); !ok {
+		return result, false
+	}
+	if err := request.ParseForm(); err != nil {
+		return readBodyError(err, ErrMalformedRequestForm), false
+	}
+	return bindForm(request, request.PostForm, v)
+}
+
+// FileUpload describes a file streamed from a multipart/form-data request body.
+type FileUpload struct {
+	// Field is the name of the form field under which the file was uploaded.
+	Field string
+	// Filename is the name of the file as supplied by the client.
+	Filename string
+	// ContentType is the media type detected from the first bytes of the file (see http.DetectContentType),
+	// regardless of any type declared by the client.
+	ContentType string
+	// Content is the content of the file, which is only valid until the FileSink returns.
+	Content io.Reader
+}
+
+// FileSink receives each file uploaded in a multipart/form-data request body, in the order they appear, and is
+// expected to consume (or store) the Content before returning. Any error returned, other than one caused by reading
+// the Content, results in ErrInternalServerError with 500 Internal Server Error.
+type FileSink func(FileUpload) error
+
+// ReadMultipartRequestBody ensures the Content-Type header indicates multipart/form-data and if so, proceeds to
+// stream the body part by part, rather than buffering it in memory or temporary files. Form values are used to
+// populate the fields of v tagged with `form:"name"` (see Bind), which may be nil. Files are passed to the sink, which
+// may be nil if files are to be discarded, after checking their type against Read.AllowedFileTypes. Failure at any
+// point results in a JSON error which can be sent to the client with Flush. The options, if any, are applied after
+// those supplied to SetDefaultReadOptions. In addition to Read.MaxBytes, which limits the body as a whole, each file
+// is limited by Read.MaxFileBytes.
+func ReadMultipartRequestBody(request *http.Request, v any, sink FileSink, options ...ReadOption) (ResponseOption, bool) {
+	config := newReadConfig(options)
+	if !hasMediaType(request, multipartContentType) {
+		return Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), false
+	}
+	if result, ok := config.prepareBody(request); !ok {
+		return result, false
+	}
+	reader, err := request.MultipartReader()
+	if err != nil {
+		return Response.JSONErrors(http.StatusBadRequest, ErrMalformedRequestForm), false
+	}
+	values := make(url.Values)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return readBodyError(err, ErrMalformedRequestForm), false
+		}
+		if part.FileName() == "" {
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return readBodyError(err, ErrMalformedRequestForm), false
+			}
+			values.Add(part.FormName(), string(value))
+			continue
+		}
+		if result, ok := config.receiveFile(part, sink); !ok {
+			return result, false
+		}
+	}
+	return bindForm(request, values, v)
+}
+
+func (this readConfig) receiveFile(part *multipart.Part, sink FileSink) (ResponseOption, bool) {
+	field := part.FormName()
+	upload := &uploadReader{reader: part, limit: this.maxFileBytes}
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(upload, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return upload.failure(field), false
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head)) // the declared type can't be trusted
+	if !this.allowsFileType(contentType) {
+		return Response.JSONErrors(http.StatusUnsupportedMediaType, fieldError(ErrUnsupportedRequestFileType, "form."+field)), false
+	}
+	content := io.MultiReader(bytes.NewReader(head), upload)
+	err = nil
+	if sink != nil {
+		err = sink(FileUpload{Field: field, Filename: part.FileName(), ContentType: contentType, Content: content})
+	}
+	if err == nil {
+		_, err = io.Copy(io.Discard, content) // enforces the limits on whatever the sink didn't consume
+	}
+	if upload.err != nil {
+		return upload.failure(field), false
+	}
+	if err != nil {
+		return Response.JSONErrors(http.StatusInternalServerError, ErrInternalServerError), false
+	}
+	return nil, true
+}
+func (this readConfig) allowsFileType(contentType string) bool {
+	return len(this.allowedFileTypes) == 0 || matchesMediaType(this.allowedFileTypes, contentType)
+}
+
+// matchesMediaType reports whether the media type matches any of the patterns, each of which may be a wildcard (ie.
+// "image/*" or "*/*").
+func matchesMediaType(patterns []string, mediaType string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*/*" {
+			return true
+		}
+		if prefix, wildcard := strings.CutSuffix(pattern, "/*"); wildcard && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+		if strings.EqualFold(pattern, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadReader limits the size of a file and records any error encountered while reading it, so that problems with
+// the request body can be distinguished from errors originating in the FileSink.
+type uploadReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+	err    error
+}
+
+func (this *uploadReader) Read(p []byte) (n int, err error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	if this.limit > 0 && int64(len(p)) > this.limit-this.read+1 {
+		p = p[:this.limit-this.read+1]
+	}
+	n, err = this.reader.Read(p)
+	this.read += int64(n)
+	if this.limit > 0 && this.read > this.limit {
+		n, err = n-int(this.read-this.limit), errUploadTooLarge
+		this.read = this.limit
+	}
+	if err != nil && err != io.EOF {
+		this.err = err
+	}
+	return n, err
+}
+func (this *uploadReader) failure(field string) ResponseOption {
+	if errors.Is(this.err, errUploadTooLarge) {
+		return Response.JSONErrors(http.StatusRequestEntityTooLarge, fieldError(ErrRequestFileTooLarge, "form."+field))
+	}
+	return readBodyError(this.err, ErrMalformedRequestForm)
+}
+
+func bindForm(request *http.Request, values url.Values, v any) (ResponseOption, bool) {
+	if v == nil {
+		return nil, true
+	}
+	return (&binder{request: request, sources: formBindSources, form: values}).bind(v)
+}
+func hasMediaType(request *http.Request, expected string) bool {
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get(headerContentType))
+	return strings.EqualFold(mediaType, expected)
+}
+
+// fieldError returns a copy of the template error whose Fields identify the offending field.
+func fieldError(template Error, field string) Error {
+	template.Fields = []string{field}
+	return template
+}
+
+var (
+	formBindSources   = []string{"form"}
+	errUploadTooLarge = errors.New("scuter: file exceeded the maximum allowed size")
+)
+
+const (
+	formContentType      = "application/x-www-form-urlencoded"
+	multipartContentType = "multipart/form-data"
+	sniffLength          = 512 // the number of bytes considered by http.DetectContentType
+)
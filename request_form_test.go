@@ -0,0 +1,223 @@
+package scuter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+type formModel struct {
+	Name  string `form:"name"`
+	Count int    `form:"count"`
+	Page  int    `query:"page"`
+}
+
+func TestReadFormRequestBody(t *testing.T) {
+	request := newFormRequest(t, url.Values{"name": {"Gopher"}, "count": {"3"}}, Request.Query("page", "2"))
+	var model formModel
+
+	actual, ok := ReadFormRequestBody(request, &model)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, model, should.Equal, formModel{Name: "Gopher", Count: 3})
+}
+func TestReadFormRequestBody_NilModel(t *testing.T) {
+	request := newFormRequest(t, url.Values{"name": {"Gopher"}})
+
+	actual, ok := ReadFormRequestBody(request, nil)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, request.PostForm.Get("name"), should.Equal, "Gopher")
+}
+func TestReadFormRequestBody_UnsupportedContentType(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.JSONBody(map[string]string{"name": "Gopher"}))
+
+	actual, ok := ReadFormRequestBody(request, nil)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), actual)
+}
+func TestReadFormRequestBody_TooLarge(t *testing.T) {
+	request := newFormRequest(t, url.Values{"name": {"Gopher"}})
+
+	actual, ok := ReadFormRequestBody(request, nil, Read.MaxBytes(4))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadFormRequestBody_MalformedValue(t *testing.T) {
+	request := newFormRequest(t, url.Values{"count": {"many"}})
+	var model formModel
+
+	actual, ok := ReadFormRequestBody(request, &model)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, Error{
+		Fields:  []string{"form.count"},
+		Name:    "malformed-request-value",
+		Message: "The value must be an integer.",
+	}), actual)
+}
+func newFormRequest(t *testing.T, values url.Values, options ...RequestOption) *http.Request {
+	return NewTestRequest(t.Context(), "POST", "/", Request.With(options...), Request.With(
+		Request.Header(headerContentType, "application/x-www-form-urlencoded"),
+		Request.Body(strings.NewReader(values.Encode())),
+	))
+}
+
+func TestReadMultipartRequestBody(t *testing.T) {
+	request := newMultipartRequest(t,
+		multipartField{name: "name", value: "Gopher"},
+		multipartField{name: "avatar", filename: "a.png", value: "\x89PNG\r\n\x1a\n..."},
+		multipartField{name: "notes", filename: "notes.txt", contentType: "text/plain; charset=utf-8", value: "hello"},
+		multipartField{name: "count", value: "3"},
+	)
+	var model formModel
+	var uploads []FileUpload
+	var contents []string
+
+	actual, ok := ReadMultipartRequestBody(request, &model, func(upload FileUpload) error {
+		content, err := io.ReadAll(upload.Content)
+		upload.Content = nil
+		uploads = append(uploads, upload)
+		contents = append(contents, string(content))
+		return err
+	}, Read.AllowedFileTypes("image/*", "text/plain"))
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, model, should.Equal, formModel{Name: "Gopher", Count: 3})
+	should.So(t, uploads, should.Equal, []FileUpload{
+		{Field: "avatar", Filename: "a.png", ContentType: "image/png"},
+		{Field: "notes", Filename: "notes.txt", ContentType: "text/plain"},
+	})
+	should.So(t, contents, should.Equal, []string{"\x89PNG\r\n\x1a\n...", "hello"})
+}
+func TestReadMultipartRequestBody_NilSinkAndModel(t *testing.T) {
+	request := newMultipartRequest(t, multipartField{name: "file", filename: "a.txt", value: "hello"})
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+}
+func TestReadMultipartRequestBody_UnsupportedContentType(t *testing.T) {
+	request := newFormRequest(t, url.Values{"name": {"Gopher"}})
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), actual)
+}
+func TestReadMultipartRequestBody_UnsupportedFileType(t *testing.T) {
+	request := newMultipartRequest(t, multipartField{name: "avatar", filename: "a.exe", value: "MZ\x90\x00"})
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil, Read.AllowedFileTypes("image/*"))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType, Error{
+		Fields:  []string{"form.avatar"},
+		Name:    "unsupported-file-type",
+		Message: "The type of the file was not supported.",
+	}), actual)
+}
+func TestReadMultipartRequestBody_DeclaredFileTypeIgnored(t *testing.T) {
+	request := newMultipartRequest(t, multipartField{name: "avatar", filename: "a.png", contentType: "image/png", value: "MZ\x90\x00"})
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil, Read.AllowedFileTypes("image/*"))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType,
+		fieldError(ErrUnsupportedRequestFileType, "form.avatar")), actual)
+}
+func TestReadMultipartRequestBody_FileAtLimit(t *testing.T) {
+	request := newMultipartRequest(t, multipartField{name: "file", filename: "a.txt", value: "12345"})
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil, Read.MaxFileBytes(5))
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+}
+func TestReadMultipartRequestBody_FileTooLarge(t *testing.T) {
+	expected := Response.JSONErrors(http.StatusRequestEntityTooLarge, Error{
+		Fields:  []string{"form.file"},
+		Name:    "request-file-too-large",
+		Message: "The file exceeded the maximum allowed size.",
+	})
+	for _, sink := range []FileSink{
+		nil,
+		func(upload FileUpload) error { _, err := io.ReadAll(upload.Content); return err },
+		func(upload FileUpload) error { return nil },
+	} {
+		request := newMultipartRequest(t, multipartField{name: "file", filename: "a.txt", value: strings.Repeat("x", 1024)})
+
+		actual, ok := ReadMultipartRequestBody(request, nil, sink, Read.MaxFileBytes(600))
+
+		should.So(t, ok, should.BeFalse)
+		assertResponseEqual(t, expected, actual)
+	}
+}
+func TestReadMultipartRequestBody_BodyTooLarge(t *testing.T) {
+	request := newMultipartRequest(t, multipartField{name: "file", filename: "a.txt", value: strings.Repeat("x", 1024)})
+	request.ContentLength = -1
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil, Read.MaxBytes(512))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadMultipartRequestBody_SinkError(t *testing.T) {
+	request := newMultipartRequest(t, multipartField{name: "file", filename: "a.txt", value: "hello"})
+
+	actual, ok := ReadMultipartRequestBody(request, nil, func(FileUpload) error { return errors.New("disk full") })
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusInternalServerError, ErrInternalServerError), actual)
+}
+func TestReadMultipartRequestBody_Malformed(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header(headerContentType, "multipart/form-data; boundary=abc"),
+		Request.Body(strings.NewReader("--abc\r\nnonsense")),
+	))
+
+	actual, ok := ReadMultipartRequestBody(request, nil, nil)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, ErrMalformedRequestForm), actual)
+}
+
+type multipartField struct{ name, filename, contentType, value string }
+
+func newMultipartRequest(t *testing.T, fields ...multipartField) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for _, field := range fields {
+		header := make(textproto.MIMEHeader)
+		disposition := `form-data; name="` + field.name + `"`
+		if field.filename != "" {
+			disposition += `; filename="` + field.filename + `"`
+		}
+		header.Set("Content-Disposition", disposition)
+		if field.contentType != "" {
+			header.Set(headerContentType, field.contentType)
+		}
+		part, err := writer.CreatePart(header)
+		should.So(t, err, should.BeNil)
+		_, _ = io.WriteString(part, field.value)
+	}
+	should.So(t, writer.Close(), should.BeNil)
+	return NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header(headerContentType, writer.FormDataContentType()),
+		Request.Body(body),
+	))
+}
@@ -0,0 +1,129 @@
+package scuter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// decodeJSONRequestBody unmarshals data into v, translating any failure into a JSON error response which describes
+// where the problem occurred (see decodeJSON).
+func decodeJSONRequestBody(data []byte, v any, strict bool) (ResponseOption, bool) {
+	if errs := decodeJSON(data, v, strict); len(errs) > 0 {
+		return Response.JSONErrors(http.StatusBadRequest, errs...), false
+	}
+	return nil, true
+}
+
+// decodeJSON unmarshals data into v, returning the errors which describe where any problems occurred (see
+// describeJSONError), each of whose Fields are rooted at "body".
+func decodeJSON(data []byte, v any, strict bool) []Error {
+	var err error
+	if strict {
+		var errs []Error
+		if errs, err = scanStrictJSON(data, v); err == nil && len(errs) > 0 {
+			return errs
+		} else if err == nil {
+			err = json.Unmarshal(data, &v)
+		}
+	} else {
+		err = json.NewDecoder(bytes.NewReader(data)).Decode(&v) // FUTURE: upgrade to json/v2's json.UnmarshalRead
+	}
+	if err != nil {
+		return []Error{describeJSONError(data, v, err)}
+	}
+	return nil
+}
+
+// describeJSONError returns a copy of ErrInvalidRequestJSONBody whose Fields contain the JSON path of the offending
+// value (ie. "body.items[2].due_date") and whose Message explains what was expected there. Syntax errors refer to
+// the body as a whole and include the line and column at which the problem was found.
+func describeJSONError(data []byte, v any, err error) Error {
+	result := ErrInvalidRequestJSONBody
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr) && !strings.HasPrefix(syntaxErr.Error(), "unexpected end"):
+		result.Message = describeJSONSyntaxError(data, syntaxErr.Offset-1, syntaxErr.Error()) // Offset follows the offending byte
+	case syntaxErr != nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		result.Message = describeJSONSyntaxError(data, int64(len(data)), "unexpected end of JSON input")
+	case errors.As(err, &typeErr):
+		result.Fields = []string{jsonFieldPath(reflect.TypeOf(v), typeErr.Field)}
+		result.Message = fmt.Sprintf("The value must be %s.", describeJSONType(typeErr.Type))
+	default: // an error returned by a json.Unmarshaler or encoding.TextUnmarshaler, which doesn't indicate the field
+		if path, target, found := locateJSONError(data, v); found {
+			result.Fields = []string{path}
+			result.Message = fmt.Sprintf("The value must be %s.", describeJSONType(target))
+		}
+	}
+	return result
+}
+func describeJSONSyntaxError(data []byte, offset int64, problem string) string {
+	offset = min(max(offset, 0), int64(len(data)))
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := 1 + int(offset) - (bytes.LastIndexByte(data[:offset], '\n') + 1)
+	return fmt.Sprintf("The body was not well-formed JSON (%s at line %d, column %d).", problem, line, column)
+}
+func describeJSONType(target reflect.Type) string {
+	if target == timeType {
+		return `an RFC 3339 timestamp (ie. "2006-01-02T15:04:05Z")`
+	}
+	switch target.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := target.Bits()
+		return fmt.Sprintf("an integer between %d and %d", int64(-1)<<(bits-1), int64(1)<<(bits-1)-1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("an integer between 0 and %d", uint64(math.MaxUint64)>>(64-target.Bits()))
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Pointer:
+		return describeJSONType(target.Elem())
+	}
+	return "a valid " + target.String()
+}
+
+// jsonFieldPath converts the dotted field path reported by encoding/json (ie. "items.0.id") into the notation used
+// in Error.Fields (ie. "body.items[0].id"), following target (the type of the value being unmarshaled) to tell the
+// elements of arrays from the members of objects (whose keys might well be numeric).
+func jsonFieldPath(target reflect.Type, field string) string {
+	path := "body"
+	for element := range strings.SplitSeq(field, ".") {
+		if element == "" {
+			continue
+		}
+		if target = strictJSONType(target); target != nil && (target.Kind() == reflect.Slice || target.Kind() == reflect.Array) {
+			path += "[" + element + "]"
+			target = target.Elem()
+		} else {
+			path += "." + element
+			target, _, _ = strictJSONMemberType(target, element)
+		}
+	}
+	return path
+}
+
+// locateJSONError returns the path and type of the first value which its own unmarshaling method rejects.
+func locateJSONError(data []byte, v any) (path string, target reflect.Type, found bool) {
+	this := newStrictJSONScanner(data)
+	this.locate = true
+	if err := this.scanValue(strictJSONType(reflect.TypeOf(v)), "body", 0); err != errJSONLocated {
+		return "", nil, false
+	}
+	return this.locatedPath, this.locatedType, true
+}
+
+var errJSONLocated = errors.New("json: located")
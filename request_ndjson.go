@@ -0,0 +1,120 @@
+package scuter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrRequestLineTooLarge = Error{
+	Name:    "request-line-too-large",
+	Message: "The line exceeded the maximum allowed size.",
+}
+
+// ReadNDJSONRequestBody ensures the Content-Type header indicates newline-delimited JSON (ie. application/x-ndjson or
+// application/jsonl) and if so, returns a sequence which reads and unmarshals the body one record (line) at a time,
+// so that bodies of any number of records can be processed without first collecting them in memory. Blank lines are
+// skipped. A record which can't be unmarshaled (or which exceeds Read.MaxLineBytes) is yielded as an Error, like
+// those returned by ReadJSONRequestBody, whose Fields identify the record by its line number (ie. "body[1234]" or
+// "body[1234].items[2].name"), after which the sequence continues with the next line. The body as a whole is only
+// limited by an explicit Read.MaxBytes (supplied here or to SetDefaultReadOptions), not by the default limit of the
+// other readers, so that bulk imports of any size succeed by default; exceeding it ends the sequence with
+// ErrRequestBodyTooLarge, whereas any other failure to read the body ends it with the error returned by the body.
+// The sequence may only be iterated once. The options, if any, are applied after those supplied to
+// SetDefaultReadOptions.
+func ReadNDJSONRequestBody[T any](request *http.Request, options ...ReadOption) (iter.Seq2[T, error], ResponseOption, bool) {
+	config := newReadConfig(options)
+	if !config.explicitMaxBytes {
+		config.maxBytes = 0 // records are limited individually (see Read.MaxLineBytes)
+	}
+	if !hasMediaType(request, ndjsonContentType) && !hasMediaType(request, jsonLinesContentType) {
+		return nil, Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), false
+	}
+	if result, ok := config.prepareBody(request); !ok {
+		return nil, result, false
+	}
+	return func(yield func(T, error) bool) {
+		reader := &ndjsonReader{reader: bufio.NewReader(request.Body), limit: config.maxLineBytes}
+		for number := 1; ; number++ {
+			line, err := reader.readLine()
+			if err != nil && err != io.EOF {
+				var zero T
+				yield(zero, ndjsonReadError(err))
+				return
+			}
+			record, blank, failure := decodeNDJSONLine[T](reader, line, number, config.strict)
+			if !blank && !yield(record, failure) {
+				return
+			}
+			if err == io.EOF {
+				return
+			}
+		}
+	}, nil, true
+}
+
+// decodeNDJSONLine unmarshals the line, unless it's blank (and therefore not a record).
+func decodeNDJSONLine[T any](reader *ndjsonReader, line []byte, number int, strict bool) (record T, blank bool, err error) {
+	if reader.tooLarge {
+		return record, false, fieldError(ErrRequestLineTooLarge, ndjsonFieldPath(number))
+	}
+	if len(bytes.TrimSpace(line)) == 0 {
+		return record, true, nil
+	}
+	if errs := decodeJSON(line, &record, strict); len(errs) > 0 {
+		failure := errs[0] // one error per record, so that each can be reported alongside the records which succeeded
+		failure.Fields = []string{ndjsonFieldPath(number, errs[0].Fields...)}
+		return record, false, failure
+	}
+	return record, false, nil
+}
+
+// ndjsonFieldPath rewrites a JSON path rooted at "body" (see jsonFieldPath) to be rooted at the numbered line (ie.
+// "body[1234].name").
+func ndjsonFieldPath(number int, fields ...string) string {
+	path := "body"
+	if len(fields) > 0 {
+		path = fields[0]
+	}
+	return "body[" + strconv.Itoa(number) + "]" + strings.TrimPrefix(path, "body")
+}
+func ndjsonReadError(err error) error {
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		return ErrRequestBodyTooLarge
+	}
+	return err
+}
+
+// ndjsonReader reads a body one line at a time, discarding (rather than accumulating) the remainder of any line
+// which exceeds the limit.
+type ndjsonReader struct {
+	reader   *bufio.Reader
+	limit    int
+	line     []byte
+	tooLarge bool
+}
+
+// readLine returns the next line (without its line ending), which is only valid until the next call.
+func (this *ndjsonReader) readLine() ([]byte, error) {
+	this.line, this.tooLarge = this.line[:0], false
+	for {
+		chunk, err := this.reader.ReadSlice('\n')
+		if !this.tooLarge {
+			this.line = append(this.line, chunk...)
+			this.tooLarge = this.limit > 0 && len(bytes.TrimRight(this.line, "\r\n")) > this.limit
+		}
+		if this.tooLarge {
+			this.line = this.line[:0]
+		}
+		if err != bufio.ErrBufferFull {
+			return bytes.TrimRight(this.line, "\r\n"), err
+		}
+	}
+}
+
+const jsonLinesContentType = "application/jsonl"
@@ -0,0 +1,175 @@
+package scuter
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+type ndjsonRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestReadNDJSONRequestBody(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Body(strings.NewReader("{\"id\":1,\"name\":\"a\"}\r\n\n  \n{\"id\":2}\n{\"id\":3,\"name\":\"c\"}")),
+	))
+
+	records, actual, ok := ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	var collected []ndjsonRecord
+	for record, err := range records {
+		should.So(t, err, should.BeNil)
+		collected = append(collected, record)
+	}
+	should.So(t, collected, should.Equal, []ndjsonRecord{{ID: 1, Name: "a"}, {ID: 2}, {ID: 3, Name: "c"}})
+}
+func TestReadNDJSONRequestBody_JSONLines(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/jsonl; charset=utf-8"),
+		Request.Body(strings.NewReader(`{"id":1}`)),
+	))
+
+	_, _, ok := ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	should.So(t, ok, should.BeTrue)
+}
+func TestReadNDJSONRequestBody_UnsupportedContentType(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.JSONBody([]int{1}))
+
+	records, actual, ok := ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	should.So(t, ok, should.BeFalse)
+	should.So(t, records, should.BeNil)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType), actual)
+}
+func TestReadNDJSONRequestBody_NoDefaultBodyLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Body(strings.NewReader("{\"id\":1}\n")),
+	))
+	request.ContentLength = defaultMaxRequestBodyBytes + 1
+
+	records, actual, ok := ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	for record, err := range records {
+		should.So(t, err, should.BeNil)
+		should.So(t, record, should.Equal, ndjsonRecord{ID: 1})
+	}
+
+	SetDefaultReadOptions(Read.MaxBytes(8))
+	defer SetDefaultReadOptions()
+	_, actual, ok = ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadNDJSONRequestBody_ContentLengthBeyondLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Body(strings.NewReader("{\"id\":1}\n{\"id\":2}\n")),
+	))
+
+	_, actual, ok := ReadNDJSONRequestBody[ndjsonRecord](request, Read.MaxBytes(10))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadNDJSONRequestBody_MalformedLines(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Body(strings.NewReader("{\"id\":1}\n{invalid\n{\"id\":\"3\"}\n{\"id\":4,\"unknown\":true}\n{\"id\":5}\n")),
+	))
+
+	records, _, ok := ReadNDJSONRequestBody[ndjsonRecord](request, Read.Strict(true))
+
+	should.So(t, ok, should.BeTrue)
+	var ids []int
+	var failures []Error
+	for record, err := range records {
+		if err != nil {
+			failures = append(failures, err.(Error))
+		} else {
+			ids = append(ids, record.ID)
+		}
+	}
+	should.So(t, ids, should.Equal, []int{1, 5})
+	should.So(t, failures, should.Equal, []Error{
+		{
+			Fields:  []string{"body[2]"},
+			Name:    "malformed-request-payload",
+			Message: "The body was not well-formed JSON (invalid character 'i' looking for beginning of value at line 1, column 2).",
+		},
+		{
+			Fields:  []string{"body[3].id"},
+			Name:    "malformed-request-payload",
+			Message: "The value must be an integer between -9223372036854775808 and 9223372036854775807.",
+		},
+		fieldError(ErrUnknownRequestField, "body[4].unknown"),
+	})
+}
+func TestReadNDJSONRequestBody_LineBeyondLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Body(strings.NewReader("{\"id\":1}\r\n{\"id\":2,\"name\":\""+strings.Repeat("x", 8192)+"\"}\n{\"id\":3}")),
+	))
+
+	records, _, _ := ReadNDJSONRequestBody[ndjsonRecord](request, Read.MaxLineBytes(8))
+
+	var ids []int
+	var failures []error
+	for record, err := range records {
+		if err != nil {
+			failures = append(failures, err)
+		} else {
+			ids = append(ids, record.ID)
+		}
+	}
+	should.So(t, ids, should.Equal, []int{1, 3})
+	should.So(t, failures, should.Equal, []error{fieldError(ErrRequestLineTooLarge, "body[2]")})
+}
+func TestReadNDJSONRequestBody_ChunkedBodyBeyondLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.Header("Content-Type", "application/x-ndjson"))
+	request.ContentLength = -1
+	request.TransferEncoding = []string{"chunked"}
+	request.Body = io.NopCloser(strings.NewReader("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"))
+
+	records, _, ok := ReadNDJSONRequestBody[ndjsonRecord](request, Read.MaxBytes(12))
+
+	should.So(t, ok, should.BeTrue)
+	var ids []int
+	var failures []error
+	for record, err := range records {
+		if err != nil {
+			failures = append(failures, err)
+		} else {
+			ids = append(ids, record.ID)
+		}
+	}
+	should.So(t, ids, should.Equal, []int{1})
+	should.So(t, failures, should.Equal, []error{ErrRequestBodyTooLarge})
+}
+func TestReadNDJSONRequestBody_StopEarly(t *testing.T) {
+	request := NewTestRequest(t.Context(), "POST", "/", Request.With(
+		Request.Header("Content-Type", "application/x-ndjson"),
+		Request.Body(strings.NewReader("{\"id\":1}\n{\"id\":2}\n")),
+	))
+
+	records, _, _ := ReadNDJSONRequestBody[ndjsonRecord](request)
+
+	count := 0
+	for range records {
+		count++
+		break
+	}
+	should.So(t, count, should.Equal, 1)
+}
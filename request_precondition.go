@@ -0,0 +1,83 @@
+package scuter
+
+import (
+	"net/http"
+	"time"
+)
+
+var (
+	ErrPreconditionFailed = Error{
+		Name:    "precondition-failed",
+		Message: "The resource has been modified since it was retrieved.",
+	}
+	ErrPreconditionRequired = Error{
+		Fields:  []string{"header.If-Match"},
+		Name:    "precondition-required",
+		Message: "The request must be conditional on the current version of the resource.",
+	}
+)
+
+// Precondition is the condition, supplied via the If-Match and If-Unmodified-Since headers, under which a request
+// (ie. PUT, PATCH, or DELETE) may modify a resource, which guards against overwriting changes made by others since
+// the client retrieved the resource (see Response.ETag and Response.LastModified).
+type Precondition struct {
+	ifMatch         []string
+	unmodifiedSince time.Time
+}
+
+// ReadPrecondition returns the Precondition of the request. A malformed If-Unmodified-Since header is ignored, as
+// is If-Unmodified-Since whenever If-Match is present (RFC 9110, section 13.2.2).
+func ReadPrecondition(request *http.Request) Precondition {
+	if values := request.Header.Values(headerIfMatch); len(values) > 0 {
+		return Precondition{ifMatch: values}
+	}
+	since, _ := http.ParseTime(request.Header.Get(headerIfUnmodifiedSince))
+	return Precondition{unmodifiedSince: since}
+}
+
+// IsZero reports whether the request is unconditional.
+func (this Precondition) IsZero() bool {
+	return len(this.ifMatch) == 0 && this.unmodifiedSince.IsZero()
+}
+
+// Matches reports whether the current version of the resource, identified by its etag (as supplied to
+// Response.ETag, which is empty when the resource doesn't exist) and the time it was last modified (which is zero
+// when unknown), satisfies the precondition. If-Match lists entity tags (ie. `"v41", "v42"`), one of which must
+// equal the etag by strong comparison, such that weak entity tags (ie. `W/"v42"`) never match, or else "*", which
+// matches any etag. If-Unmodified-Since is satisfied unless the resource was modified after the supplied time.
+// An unconditional request matches anything.
+func (this Precondition) Matches(etag string, modified time.Time) bool {
+	if len(this.ifMatch) > 0 {
+		return etag != "" && matchETag(this.ifMatch, `"`+etag+`"`, true)
+	}
+	return this.unmodifiedSince.IsZero() || modified.IsZero() || !modified.Truncate(time.Second).After(this.unmodifiedSince)
+}
+
+// Evaluate returns a JSON error response which can be sent to the client with Flush, containing
+// ErrPreconditionFailed with 412 Precondition Failed, unless the precondition matches the current version of the
+// resource (see Matches), in which case it returns nil and true.
+func (this Precondition) Evaluate(etag string, modified time.Time) (ResponseOption, bool) {
+	if this.Matches(etag, modified) {
+		return nil, true
+	}
+	field := "header." + headerIfMatch
+	if len(this.ifMatch) == 0 {
+		field = "header." + headerIfUnmodifiedSince
+	}
+	return Response.JSONErrors(http.StatusPreconditionFailed, fieldError(ErrPreconditionFailed, field)), false
+}
+
+// Require returns a JSON error response which can be sent to the client with Flush, containing
+// ErrPreconditionRequired with 428 Precondition Required, for endpoints that refuse unconditional requests (see
+// IsZero), or else nil and true.
+func (this Precondition) Require() (ResponseOption, bool) {
+	if this.IsZero() {
+		return Response.JSONErrors(http.StatusPreconditionRequired, ErrPreconditionRequired), false
+	}
+	return nil, true
+}
+
+var (
+	headerIfMatch           = "If-Match"
+	headerIfUnmodifiedSince = "If-Unmodified-Since"
+)
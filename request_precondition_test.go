@@ -0,0 +1,104 @@
+package scuter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestReadPrecondition_IfMatch(t *testing.T) {
+	for condition, expected := range map[string]bool{
+		`"v42"`:         true,
+		`"v41", "v42"`:  true,
+		`"a,b","v42"`:   true,
+		`*`:             true,
+		`W/"v42"`:       false, // weak entity tags never match
+		`"v41"`:         false,
+		`v42`:           false,
+		`"v41", "v42`:   false,
+		`"v41" garbage`: false,
+	} {
+		request := NewTestRequest(t.Context(), http.MethodPut, "/", Request.Header("If-Match", condition))
+
+		precondition := ReadPrecondition(request)
+
+		should.So(t, precondition.IsZero(), should.BeFalse)
+		should.So(t, precondition.Matches("v42", time.Time{}), should.Equal, expected)
+	}
+}
+func TestReadPrecondition_IfMatchMissingResource(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodPut, "/", Request.Header("If-Match", "*"))
+
+	precondition := ReadPrecondition(request)
+
+	should.So(t, precondition.Matches("", time.Time{}), should.BeFalse)
+}
+func TestReadPrecondition_IfUnmodifiedSince(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodPut, "/", Request.Header("If-Unmodified-Since", "Mon, 06 May 2024 13:08:09 GMT"))
+	since := time.Date(2024, 5, 6, 13, 8, 9, 0, time.UTC)
+
+	precondition := ReadPrecondition(request)
+
+	should.So(t, precondition.IsZero(), should.BeFalse)
+	should.So(t, precondition.Matches("", since), should.BeTrue)
+	should.So(t, precondition.Matches("", since.Add(time.Millisecond)), should.BeTrue)
+	should.So(t, precondition.Matches("", since.Add(-time.Hour)), should.BeTrue)
+	should.So(t, precondition.Matches("", since.Add(time.Second)), should.BeFalse)
+	should.So(t, precondition.Matches("", time.Time{}), should.BeTrue)
+}
+func TestReadPrecondition_IfMatchTakesPrecedence(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodPut, "/", Request.With(
+		Request.Header("If-Match", `"v42"`),
+		Request.Header("If-Unmodified-Since", "Mon, 06 May 2024 13:08:09 GMT"),
+	))
+
+	precondition := ReadPrecondition(request)
+
+	should.So(t, precondition.Matches("v42", time.Now()), should.BeTrue)
+}
+func TestReadPrecondition_Unconditional(t *testing.T) {
+	for _, request := range []*http.Request{
+		NewTestRequest(t.Context(), http.MethodPut, "/"),
+		NewTestRequest(t.Context(), http.MethodPut, "/", Request.Header("If-Unmodified-Since", "yesterday")),
+	} {
+		precondition := ReadPrecondition(request)
+
+		should.So(t, precondition.IsZero(), should.BeTrue)
+		should.So(t, precondition.Matches("v1", time.Now()), should.BeTrue)
+	}
+}
+func TestPreconditionEvaluate(t *testing.T) {
+	ifMatch := ReadPrecondition(NewTestRequest(t.Context(), http.MethodPut, "/", Request.Header("If-Match", `"v41"`)))
+	ifUnmodifiedSince := ReadPrecondition(NewTestRequest(t.Context(), http.MethodPut, "/",
+		Request.Header("If-Unmodified-Since", "Mon, 06 May 2024 13:08:09 GMT")))
+
+	matched, matchedOK := ifMatch.Evaluate("v41", time.Time{})
+	stale, staleOK := ifMatch.Evaluate("v42", time.Time{})
+	modified, modifiedOK := ifUnmodifiedSince.Evaluate("v42", time.Now())
+
+	should.So(t, matchedOK, should.BeTrue)
+	should.So(t, matched, should.BeNil)
+	should.So(t, staleOK, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusPreconditionFailed, Error{
+		Fields:  []string{"header.If-Match"},
+		Name:    "precondition-failed",
+		Message: "The resource has been modified since it was retrieved.",
+	}), stale)
+	should.So(t, modifiedOK, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusPreconditionFailed,
+		fieldError(ErrPreconditionFailed, "header.If-Unmodified-Since")), modified)
+}
+func TestPreconditionRequire(t *testing.T) {
+	unconditional := ReadPrecondition(NewTestRequest(t.Context(), http.MethodPut, "/"))
+	conditional := ReadPrecondition(NewTestRequest(t.Context(), http.MethodPut, "/", Request.Header("If-Match", `"v1"`)))
+
+	required, requiredOK := unconditional.Require()
+	satisfied, satisfiedOK := conditional.Require()
+
+	should.So(t, requiredOK, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusPreconditionRequired, ErrPreconditionRequired), required)
+	should.So(t, satisfiedOK, should.BeTrue)
+	should.So(t, satisfied, should.BeNil)
+}
@@ -0,0 +1,132 @@
+package scuter
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+var (
+	ErrMissingRequestValue = Error{
+		Name:    "missing-request-value",
+		Message: "The value is required.",
+	}
+)
+
+// RequestReader reads typed values from the path, query, and headers of a request. Unlike the lenient ReadXxx
+// functions, which treat malformed values as if they were absent, a RequestReader records an ErrMalformedRequestValue
+// for each value it couldn't parse (and an ErrMissingRequestValue for each absent value read via Required), with
+// Fields identifying the offending value (ie. "header.X-Count"). Once all values have been read, ErrorResponse
+// reports whether anything went wrong.
+type RequestReader struct {
+	state    *requestReaderState
+	required bool
+}
+type requestReaderState struct {
+	request *http.Request
+	query   url.Values
+	errs    []Error
+}
+
+func NewRequestReader(request *http.Request) *RequestReader {
+	return &RequestReader{state: &requestReaderState{request: request}}
+}
+
+// Required returns a view of the same reader for which absent values are recorded as errors.
+func (this *RequestReader) Required() *RequestReader {
+	return &RequestReader{state: this.state, required: true}
+}
+
+// Errors returns all errors recorded so far.
+func (this *RequestReader) Errors() []Error { return this.state.errs }
+
+// ErrorResponse returns a JSON error response, which can be sent to the client with Flush, containing all recorded
+// errors, or nil and true if there were none. The status code is 400 Bad Request when any value was malformed, and
+// 422 Unprocessable Entity when values were merely missing.
+func (this *RequestReader) ErrorResponse() (ResponseOption, bool) {
+	if len(this.state.errs) == 0 {
+		return nil, true
+	}
+	code := http.StatusUnprocessableEntity
+	for _, err := range this.state.errs {
+		if err.Name == ErrMalformedRequestValue.Name {
+			code = http.StatusBadRequest
+			break
+		}
+	}
+	return Response.JSONErrors(code, this.state.errs...), false
+}
+
+func (this *RequestReader) PathValue(name string) string {
+	return readRequestValue[string](this, "path", name, "", this.state.request.PathValue(name))
+}
+func (this *RequestReader) IntPath(name string) int {
+	return readRequestValue[int](this, "path", name, "", this.state.request.PathValue(name))
+}
+func (this *RequestReader) Uint64Path(name string) uint64 {
+	return readRequestValue[uint64](this, "path", name, "", this.state.request.PathValue(name))
+}
+
+func (this *RequestReader) StringQuery(key string) string {
+	return readRequestValue[string](this, "query", key, "", this.queryValue(key))
+}
+func (this *RequestReader) IntQuery(key string) int {
+	return readRequestValue[int](this, "query", key, "", this.queryValue(key))
+}
+func (this *RequestReader) Uint64Query(key string) uint64 {
+	return readRequestValue[uint64](this, "query", key, "", this.queryValue(key))
+}
+func (this *RequestReader) BoolQuery(key string) bool {
+	return readRequestValue[bool](this, "query", key, "", this.queryValue(key))
+}
+func (this *RequestReader) DurationQuery(key string) time.Duration {
+	return readRequestValue[time.Duration](this, "query", key, "", this.queryValue(key))
+}
+func (this *RequestReader) TimeQuery(format, key string) time.Time {
+	return readRequestValue[time.Time](this, "query", key, format, this.queryValue(key))
+}
+
+func (this *RequestReader) StringHeader(key string) string {
+	return readRequestValue[string](this, "header", key, "", this.state.request.Header.Get(key))
+}
+func (this *RequestReader) IntHeader(key string) int {
+	return readRequestValue[int](this, "header", key, "", this.state.request.Header.Get(key))
+}
+func (this *RequestReader) Uint64Header(key string) uint64 {
+	return readRequestValue[uint64](this, "header", key, "", this.state.request.Header.Get(key))
+}
+func (this *RequestReader) BoolHeader(key string) bool {
+	return readRequestValue[bool](this, "header", key, "", this.state.request.Header.Get(key))
+}
+func (this *RequestReader) TimeHeader(format, key string) time.Time {
+	return readRequestValue[time.Time](this, "header", key, format, this.state.request.Header.Get(key))
+}
+
+func (this *RequestReader) queryValue(key string) string {
+	if this.state.query == nil {
+		this.state.query = this.state.request.URL.Query()
+	}
+	return this.state.query.Get(key)
+}
+func (this *RequestReader) record(template Error, field, message string) {
+	this.state.errs = append(this.state.errs, Error{
+		Fields:  []string{field},
+		Name:    template.Name,
+		Message: message,
+	})
+}
+
+func readRequestValue[T any](reader *RequestReader, source, name, layout, raw string) (value T) {
+	field := source + "." + name
+	if raw == "" {
+		if reader.required {
+			reader.record(ErrMissingRequestValue, field, ErrMissingRequestValue.Message)
+		}
+		return value
+	}
+	if problem := bindValue(reflect.ValueOf(&value).Elem(), raw, layout); problem != "" {
+		reader.record(ErrMalformedRequestValue, field, problem)
+	}
+	return value
+}
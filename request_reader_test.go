@@ -0,0 +1,91 @@
+package scuter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestRequestReader(t *testing.T) {
+	reader := NewRequestReader(NewTestRequest(t.Context(), http.MethodGet, "/", Request.With(
+		Request.PathValue("name", "tasks"),
+		Request.PathValue("id", "42"),
+		Request.PathValue("offset", "-1"),
+		Request.Query("q", "search"),
+		Request.Query("page", "-3"),
+		Request.Query("size", "25"),
+		Request.Query("all", "true"),
+		Request.Query("wait", "5s"),
+		Request.Query("day", "2025-01-02"),
+		Request.Header("X-Name", "Gopher"),
+		Request.Header("X-Delta", "-7"),
+		Request.Header("X-Count", "7"),
+		Request.Header("X-Debug", "1"),
+		Request.Header("X-Date", "2025-01-02"),
+	)))
+	date := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	should.So(t, reader.PathValue("name"), should.Equal, "tasks")
+	should.So(t, reader.Uint64Path("id"), should.Equal, uint64(42))
+	should.So(t, reader.IntPath("offset"), should.Equal, -1)
+	should.So(t, reader.StringQuery("q"), should.Equal, "search")
+	should.So(t, reader.IntQuery("page"), should.Equal, -3)
+	should.So(t, reader.Uint64Query("size"), should.Equal, uint64(25))
+	should.So(t, reader.BoolQuery("all"), should.BeTrue)
+	should.So(t, reader.DurationQuery("wait"), should.Equal, 5*time.Second)
+	should.So(t, reader.TimeQuery("2006-01-02", "day"), should.Equal, date)
+	should.So(t, reader.StringHeader("X-Name"), should.Equal, "Gopher")
+	should.So(t, reader.IntHeader("X-Delta"), should.Equal, -7)
+	should.So(t, reader.Uint64Header("X-Count"), should.Equal, uint64(7))
+	should.So(t, reader.BoolHeader("X-Debug"), should.BeTrue)
+	should.So(t, reader.TimeHeader("2006-01-02", "X-Date"), should.Equal, date)
+
+	actual, ok := reader.ErrorResponse()
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, len(reader.Errors()), should.Equal, 0)
+}
+func TestRequestReader_AbsentOptionalValues(t *testing.T) {
+	reader := NewRequestReader(NewTestRequest(t.Context(), http.MethodGet, "/"))
+
+	should.So(t, reader.Uint64Header("X-Count"), should.Equal, uint64(0))
+	should.So(t, reader.IntQuery("page"), should.Equal, 0)
+	should.So(t, reader.PathValue("id"), should.Equal, "")
+
+	_, ok := reader.ErrorResponse()
+	should.So(t, ok, should.BeTrue)
+}
+func TestRequestReader_MissingRequiredValues(t *testing.T) {
+	reader := NewRequestReader(NewTestRequest(t.Context(), http.MethodGet, "/"))
+
+	should.So(t, reader.Required().StringQuery("name"), should.Equal, "")
+	should.So(t, reader.Required().Uint64Path("id"), should.Equal, uint64(0))
+
+	actual, ok := reader.ErrorResponse()
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusUnprocessableEntity,
+		Error{Fields: []string{"query.name"}, Name: "missing-request-value", Message: "The value is required."},
+		Error{Fields: []string{"path.id"}, Name: "missing-request-value", Message: "The value is required."},
+	), actual)
+}
+func TestRequestReader_MalformedValues(t *testing.T) {
+	reader := NewRequestReader(NewTestRequest(t.Context(), http.MethodGet, "/", Request.With(
+		Request.Header("X-Count", "abc"),
+		Request.Query("all", "maybe"),
+	)))
+
+	should.So(t, reader.Uint64Header("X-Count"), should.Equal, uint64(0))
+	should.So(t, reader.BoolQuery("all"), should.BeFalse)
+	should.So(t, reader.TimeHeader(time.RFC1123, "X-Date"), should.Equal, time.Time{})
+	should.So(t, reader.Required().StringHeader("X-Name"), should.Equal, "")
+
+	actual, ok := reader.ErrorResponse()
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest,
+		Error{Fields: []string{"header.X-Count"}, Name: "malformed-request-value", Message: "The value must be a non-negative integer."},
+		Error{Fields: []string{"query.all"}, Name: "malformed-request-value", Message: "The value must be a boolean."},
+		Error{Fields: []string{"header.X-Name"}, Name: "missing-request-value", Message: "The value is required."},
+	), actual)
+}
@@ -1,6 +1,8 @@
 package scuter
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -29,7 +31,11 @@ func TestReadJSONRequestBody_MalformedJSON(t *testing.T) {
 	actual, ok := ReadJSONRequestBody(request, v)
 
 	should.So(t, ok, should.BeFalse)
-	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, ErrInvalidRequestJSONBody), actual)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, Error{
+		Fields:  []string{"body"},
+		Name:    "malformed-request-payload",
+		Message: "The body was not well-formed JSON (invalid character 'i' looking for beginning of object key string at line 1, column 2).",
+	}), actual)
 }
 func TestReadJSONRequestBody(t *testing.T) {
 	request := NewTestRequest(t.Context(), "PUT", "/", Request.JSONBody(map[string]any{"a": 1, "b": 2}))
@@ -87,3 +93,255 @@ func TestReadNumericPathElement(t *testing.T) {
 func assertNumericPathElement(t *testing.T, raw, element string, expected uint64) {
 	should.So(t, ReadNumericPathElement(raw, element), should.Equal, expected)
 }
+
+func TestReadJSONRequestBody_BodyAtLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(`{"a":"1234"}`)),
+	))
+	v := make(map[string]any)
+
+	actual, ok := ReadJSONRequestBody(request, &v, Read.MaxBytes(12))
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, v["a"], should.Equal, "1234")
+}
+func TestReadJSONRequestBody_ContentLengthBeyondLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(`{"a":"12345"}`)),
+	))
+	v := make(map[string]any)
+
+	actual, ok := ReadJSONRequestBody(request, v, Read.MaxBytes(12))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadJSONRequestBody_ChunkedBodyBeyondLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.Header("Content-Type", "application/json"))
+	request.ContentLength = -1
+	request.TransferEncoding = []string{"chunked"}
+	request.Body = io.NopCloser(strings.NewReader(`{"a":"12345"}`))
+	v := make(map[string]any)
+
+	actual, ok := ReadJSONRequestBody(request, v, Read.MaxBytes(12))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+}
+func TestReadJSONRequestBody_ChunkedBodyAtLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.Header("Content-Type", "application/json"))
+	request.ContentLength = -1
+	request.Body = io.NopCloser(strings.NewReader(`{"a":"1234"}`))
+	v := make(map[string]any)
+
+	actual, ok := ReadJSONRequestBody(request, v, Read.MaxBytes(12))
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+}
+func TestReadJSONRequestBody_NoLimit(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.JSONBody(map[string]string{"a": strings.Repeat("a", 64)}))
+	v := make(map[string]any)
+
+	actual, ok := ReadJSONRequestBody(request, v, Read.MaxBytes(16), Read.MaxBytes(0))
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+}
+func TestSetDefaultReadOptions(t *testing.T) {
+	defer SetDefaultReadOptions()
+	SetDefaultReadOptions(Read.MaxBytes(4))
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.JSONBody(map[string]any{"a": 1}))
+	v := make(map[string]any)
+
+	actual, ok := ReadJSONRequestBody(request, v)
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge), actual)
+	SetDefaultReadOptions()
+	should.So(t, readDefaults.maxBytes, should.Equal, int64(defaultMaxRequestBodyBytes))
+}
+
+type strictModel struct {
+	strictEmbedded
+	Name  string         `json:"name"`
+	Items []strictItem   `json:"items"`
+	Extra map[string]any `json:"extra"`
+	When  time.Time      `json:"when"`
+	Skip  string         `json:"-"`
+}
+type strictEmbedded struct {
+	Region string `json:"region"`
+}
+type strictItem struct {
+	ID int `json:"id"`
+}
+
+func TestReadJSONRequestBody_Strict(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(`{
+			"NAME": "a",
+			"region": "west",
+			"items": [{"id": 1}],
+			"extra": {"anything": {"goes": true}},
+			"when": "2025-01-02T00:00:00Z"
+		}`)),
+	))
+	var model strictModel
+
+	actual, ok := ReadJSONRequestBody(request, &model, Read.Strict(true))
+
+	should.So(t, ok, should.BeTrue)
+	should.So(t, actual, should.BeNil)
+	should.So(t, model.Name, should.Equal, "a")
+	should.So(t, model.Region, should.Equal, "west")
+	should.So(t, model.Items, should.Equal, []strictItem{{ID: 1}})
+}
+func TestReadJSONRequestBody_StrictViolations(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(`{
+			"name": "a",
+			"foo": 1,
+			"Skip": "b",
+			"items": [{"id": 1}, {"id": 2, "id": 3, "bar": {}}],
+			"name": "c"
+		} garbage`)),
+	))
+	var model strictModel
+
+	actual, ok := ReadJSONRequestBody(request, &model, Read.Strict(true))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest,
+		Error{Fields: []string{"body.foo"}, Name: "unknown-request-field", Message: "The field is not recognized."},
+		Error{Fields: []string{"body.Skip"}, Name: "unknown-request-field", Message: "The field is not recognized."},
+		Error{Fields: []string{"body.items[1].id"}, Name: "duplicate-request-field", Message: "The field appeared more than once."},
+		Error{Fields: []string{"body.items[1].bar"}, Name: "unknown-request-field", Message: "The field is not recognized."},
+		Error{Fields: []string{"body.name"}, Name: "duplicate-request-field", Message: "The field appeared more than once."},
+		ErrTrailingRequestData,
+	), actual)
+}
+func TestReadJSONRequestBody_StrictDuplicateFieldCase(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(`{"name": "a", "NAME": "b", "extra": {"key": 1, "KEY": 2}}`)),
+	))
+	var model strictModel
+
+	actual, ok := ReadJSONRequestBody(request, &model, Read.Strict(true))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest,
+		Error{Fields: []string{"body.NAME"}, Name: "duplicate-request-field", Message: "The field appeared more than once."},
+	), actual)
+}
+func TestReadJSONRequestBody_StrictMalformed(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(`{"name": `)),
+	))
+	var model strictModel
+
+	actual, ok := ReadJSONRequestBody(request, &model, Read.Strict(true))
+
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, Error{
+		Fields:  []string{"body"},
+		Name:    "malformed-request-payload",
+		Message: "The body was not well-formed JSON (unexpected end of JSON input at line 1, column 10).",
+	}), actual)
+}
+func TestReadJSONRequestBody_StrictDefault(t *testing.T) {
+	defer SetDefaultReadOptions()
+	SetDefaultReadOptions(Read.Strict(true))
+	newRequest := func() *http.Request {
+		return NewTestRequest(t.Context(), "PUT", "/", Request.With(
+			Request.Header("Content-Type", "application/json"),
+			Request.Body(strings.NewReader(`{"name": "a"} {"name": "b"}`)),
+		))
+	}
+	var model strictModel
+
+	actual, ok := ReadJSONRequestBody(newRequest(), &model)
+	should.So(t, ok, should.BeFalse)
+	assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, ErrTrailingRequestData), actual)
+
+	actual, ok = ReadJSONRequestBody(newRequest(), &model, Read.Strict(false))
+	should.So(t, ok, should.BeTrue)
+	should.So(t, model.Name, should.Equal, "a")
+}
+func TestReadJSONRequestBody_StrictTooDeep(t *testing.T) {
+	request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+		Request.Header("Content-Type", "application/json"),
+		Request.Body(strings.NewReader(strings.Repeat("[", maxStrictJSONDepth+2)+strings.Repeat("]", maxStrictJSONDepth+2))),
+	))
+	var v any
+
+	actual, ok := ReadJSONRequestBody(request, &v, Read.Strict(true))
+
+	should.So(t, ok, should.BeFalse)
+	should.So(t, assertJSONErrorFields(t, actual), should.Equal, []string{"body"})
+}
+
+type preciseModel struct {
+	DueDate time.Time      `json:"due_date"`
+	Items   []strictItem   `json:"items"`
+	Count   uint8          `json:"count"`
+	Name    string         `json:"name"`
+	Limits  map[string]int `json:"limits"`
+}
+
+func TestReadJSONRequestBody_PreciseErrors(t *testing.T) {
+	assertPreciseJSONError(t, `{"due_date": 42}`, "body.due_date",
+		`The value must be an RFC 3339 timestamp (ie. "2006-01-02T15:04:05Z").`)
+	assertPreciseJSONError(t, `{"due_date": "tomorrow"}`, "body.due_date",
+		`The value must be an RFC 3339 timestamp (ie. "2006-01-02T15:04:05Z").`)
+	assertPreciseJSONError(t, `{"items": [{"id": 1}, {"id": "2"}]}`, "body.items[1].id",
+		"The value must be an integer between -9223372036854775808 and 9223372036854775807.")
+	assertPreciseJSONError(t, `{"count": 256}`, "body.count",
+		"The value must be an integer between 0 and 255.")
+	assertPreciseJSONError(t, `{"name": true}`, "body.name",
+		"The value must be a string.")
+	assertPreciseJSONError(t, `{"limits": {"42": "x"}}`, "body.limits.42",
+		"The value must be an integer between -9223372036854775808 and 9223372036854775807.")
+	assertPreciseJSONError(t, `[]`, "body",
+		"The value must be an object.")
+	assertPreciseJSONError(t, "{\n  \"name\": \"a\",\n  \"count\": ,\n}", "body",
+		"The body was not well-formed JSON (invalid character ',' looking for beginning of value at line 3, column 12).")
+	assertPreciseJSONError(t, ``, "body",
+		"The body was not well-formed JSON (unexpected end of JSON input at line 1, column 1).")
+}
+func assertPreciseJSONError(t *testing.T, body, field, message string) {
+	t.Helper()
+	for _, strict := range []bool{false, true} {
+		request := NewTestRequest(t.Context(), "PUT", "/", Request.With(
+			Request.Header("Content-Type", "application/json"),
+			Request.Body(strings.NewReader(body)),
+		))
+		var model preciseModel
+
+		actual, ok := ReadJSONRequestBody(request, &model, Read.Strict(strict))
+
+		should.So(t, ok, should.BeFalse)
+		assertResponseEqual(t, Response.JSONErrors(http.StatusBadRequest, Error{
+			Fields:  []string{field},
+			Name:    ErrInvalidRequestJSONBody.Name,
+			Message: message,
+		}), actual)
+	}
+}
+func assertJSONErrorFields(t *testing.T, option ResponseOption) []string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	Flush(recorder, option)
+	var errs Errors
+	should.So(t, json.Unmarshal(recorder.Body.Bytes(), &errs), should.BeNil)
+	should.So(t, len(errs.Errors), should.Equal, 1)
+	should.So(t, errs.Errors[0].Name, should.Equal, ErrInvalidRequestJSONBody.Name)
+	return errs.Errors[0].Fields
+}
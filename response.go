@@ -2,12 +2,16 @@ package scuter
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -17,28 +21,38 @@ var (
 	}
 )
 
-// Flush applies the options, which may be supplied in any order, to the provide ResponseWriter.
-// IMPORTANT: errors that occur from IO operations involving the response body are silently ignored.
+// Flush applies the options, which may be supplied in any order, to the provide ResponseWriter (see DefaultFlusher).
+// IMPORTANT: errors that occur from IO operations involving the response body are not returned (see FlushErr),
+// but they are passed to any ErrorHandler supplied with Response.OnError.
 func Flush(response http.ResponseWriter, options ...ResponseOption) {
-	config := responseConfigs.Get()
-	defer responseConfigs.Put(config)
-	config.reset(response.Header())
-	Response.With(options...)(config)
-
-	response.WriteHeader(config.status)
+	DefaultFlusher.Flush(response, options...)
+}
 
-	if len(config.jsonErrors.Errors) > 0 {
-		_ = json.NewEncoder(response).Encode(config.jsonErrors) // FUTURE: upgrade to json/v2's MarshalWrite
-	} else if config.dataJSON != nil {
-		_ = json.NewEncoder(response).Encode(config.dataJSON)
-	} else if config.dataReader != nil {
-		config.writeFromReader(response, config.dataReader)
-	} else if config.data.Len() > 0 {
-		config.writeFromReader(response, &config.data)
-	}
+// FlushErr is like Flush, but also returns any errors that occur from IO operations involving the response body,
+// each of which wraps ErrResponseEncode, ErrResponseCopy, ErrResponseClose, or ErrResponseStream (see errors.Is).
+// Values are encoded before the status code is written, so a value that cannot be encoded results in
+// ErrInternalServerError with 500 Internal Server Error (along with ErrResponseEncode).
+func FlushErr(response http.ResponseWriter, options ...ResponseOption) error {
+	return DefaultFlusher.FlushErr(response, options...)
 }
 
-// ResponseOption is a callback func with an opportunity to modify the *responseConfig.
+var (
+	ErrResponseEncode = errors.New("scuter: failed to encode the response body")
+	ErrResponseCopy   = errors.New("scuter: failed to copy the response body")
+	ErrResponseClose  = errors.New("scuter: failed to close the response body reader")
+	ErrResponseStream = errors.New("scuter: failed to stream the response body")
+)
+
+// ErrorHandler receives the errors that occur from IO operations involving the response body (see FlushErr), along
+// with the context of the request (see Response.Request), or context.Background() if there is no such request.
+type ErrorHandler func(ctx context.Context, err error)
+
+// ResponseOption is a callback func with an opportunity to modify the *responseConfig. Options supplied directly to
+// Flush don't allocate, but those which escape (ie. returned from a handler func, as with a Shell, or composed with
+// Response.With and returned) cost an allocation for each that captures a value (ie. Response.JSONBody or
+// Response.JSONErrors) and for each Response.With of several options, whereas Response.StatusCode (for codes below
+// 600) and nil options are free. Returning "status + JSON body" thus costs two allocations, and "status + errors"
+// (with Response.JSONErrors) one.
 type ResponseOption func(*responseConfig)
 
 // Response is the 'namespace' for all methods that return a ResponseOption.
@@ -46,10 +60,33 @@ var Response responseSingleton
 
 type responseSingleton struct{}
 
-// With returns a 'composite' option which will be the result of calling all options in the provided order.
+// With returns a 'composite' option which will be the result of calling all options in the provided order. Nil
+// options are ignored, such that composing a single option (ie. with the result of If) returns that option.
 func (responseSingleton) With(options ...ResponseOption) ResponseOption {
+	var first, second ResponseOption
+	count := 0
+	for _, option := range options {
+		if option == nil {
+			continue
+		}
+		if count == 0 {
+			first = option
+		} else {
+			second = option
+		}
+		count++
+	}
+	switch count {
+	case 0:
+		return nil
+	case 1:
+		return first
+	case 2: // the most common composition, made without retaining the variadic slice
+		return func(config *responseConfig) { first(config); second(config) }
+	}
+	composite := slices.Clone(options) // so that the variadic slice of the caller needn't escape
 	return func(config *responseConfig) {
-		for _, option := range options {
+		for _, option := range composite {
 			if option != nil {
 				option(config)
 			}
@@ -77,52 +114,117 @@ func (responseSingleton) ContentType(mime string) ResponseOption {
 
 // JSONContentType sets the 'Content-Type' header to a sensible value representing JSON.
 func (responseSingleton) JSONContentType() ResponseOption {
-	return func(config *responseConfig) { config.header.Add(headerContentType, jsonContentType) }
+	return func(config *responseConfig) { config.header.Add(headerContentType, config.flusher.jsonContentType()) }
+}
+
+// Request associates the response with the request being answered, whose context is passed to any ErrorHandler and
+// whose conditional and Range headers are evaluated against the response (see ETag, LastModified, and
+// BodyFromReader).
+func (responseSingleton) Request(request *http.Request) ResponseOption {
+	return func(config *responseConfig) { config.request = request }
+}
+
+// OnError registers the handler to receive any errors that occur from IO operations involving the response body.
+func (responseSingleton) OnError(handler ErrorHandler) ResponseOption {
+	return func(config *responseConfig) {
+		if handler != nil {
+			config.errorHandlers = append(config.errorHandlers, handler)
+		}
+	}
 }
 
 // StatusCode sets the status code (and writes all headers).
 func (responseSingleton) StatusCode(code int) ResponseOption {
+	if code >= 0 && code < len(statusCodeOptions) {
+		return statusCodeOptions[code]
+	}
 	return func(config *responseConfig) { config.status = code }
 }
 
+// statusCodeOptions holds an option for each of the conventional status codes, so that they needn't be allocated.
+var statusCodeOptions = func() (options [600]ResponseOption) {
+	for code := range options {
+		options[code] = func(config *responseConfig) { config.status = code }
+	}
+	return options
+}()
+
 // BytesBody writes the bytes to the ResponseWriter and returns any error.
 func (responseSingleton) BytesBody(b []byte) ResponseOption {
 	return func(config *responseConfig) { _, _ = config.data.Write(b) }
 }
 
-// JSONBody uses json.MarshalWrite to serialize v to the ResponseWriter.
+// JSONBody uses the JSON Codec (see DefaultCodecs) to serialize v to the ResponseWriter.
 func (responseSingleton) JSONBody(v any) ResponseOption {
 	return func(config *responseConfig) {
-		config.header.Set(headerContentType, jsonContentType)
-		config.dataJSON = v
+		setHeader(config.header, headerContentType, config.flusher.jsonContentType())
+		config.dataValue = v
+	}
+}
+
+// Body uses the Codec registered in DefaultCodecs for the Content-Type header (which defaults to JSON when not
+// otherwise set) to serialize v to the ResponseWriter. A Content-Type with no registered Codec results in
+// ErrInternalServerError with 500 Internal Server Error.
+func (responseSingleton) Body(v any) ResponseOption {
+	return func(config *responseConfig) { config.dataValue = v }
+}
+
+// Negotiate selects the Codec (see DefaultCodecs) with which the body, or any errors, will be serialized according
+// to the Accept header of the request, setting the Content-Type header accordingly and adding 'Accept' to the Vary
+// header. When no registered Codec is acceptable, a body is replaced with ErrNotAcceptable and 406 Not Acceptable,
+// whereas errors (including ErrNotAcceptable itself) are serialized as JSON.
+func (responseSingleton) Negotiate(request *http.Request) ResponseOption {
+	return func(config *responseConfig) {
+		config.request = request
+		config.negotiate = true
 	}
 }
 
-// JSONError uses json.MarshalWrite to serialize the errors to the ResponseWriter.
+// Negotiated serializes v to the ResponseWriter using the Codec most acceptable to the request (see Negotiate).
+func (responseSingleton) Negotiated(request *http.Request, v any) ResponseOption {
+	return Response.With(Response.Negotiate(request), Response.Body(v))
+}
+
+// JSONError uses the JSON Codec (see DefaultCodecs) to serialize the errors to the ResponseWriter.
 func (responseSingleton) JSONError(err Error) ResponseOption {
 	return func(config *responseConfig) {
-		config.header.Set(headerContentType, jsonContentType)
+		setHeader(config.header, headerContentType, config.flusher.jsonContentType())
 		config.jsonErrors.Append(err)
 	}
 }
 
-// JSONErrors sets the supplied status code and uses json.MarshalWrite to serialize the errors to the ResponseWriter.
+// JSONErrors sets the supplied status code and uses the JSON Codec (see DefaultCodecs) to serialize the errors to
+// the ResponseWriter.
 func (responseSingleton) JSONErrors(code int, errs ...Error) ResponseOption {
+	if len(errs) == 1 {
+		return jsonError(code, errs[0]) // the most common case, made without retaining the variadic slice
+	}
+	clone := slices.Clone(errs)
+	return func(config *responseConfig) {
+		config.status = code
+		setHeader(config.header, headerContentType, config.flusher.jsonContentType())
+		config.jsonErrors.Append(clone...)
+	}
+}
+
+func jsonError(code int, err Error) ResponseOption {
 	return func(config *responseConfig) {
-		Response.StatusCode(code)(config)
-		config.header.Set(headerContentType, jsonContentType)
-		config.jsonErrors.Append(errs...)
+		config.status = code
+		setHeader(config.header, headerContentType, config.flusher.jsonContentType())
+		config.jsonErrors.Append(err)
 	}
 }
 
 // BodyFromReader copies from the provided io.Reader into the http.ResponseWriter and
-// calls Close() on the reader (if implemented), returning any and all errors.
+// calls Close() on the reader (if implemented), returning any and all errors. When the reader is an io.ReadSeeker
+// (ie. an *os.File or *bytes.Reader) the response advertises 'Accept-Ranges: bytes' and honors the Range and If-Range
+// headers of the request (see Response.Request), so that downloads can resume and media can seek.
 func (responseSingleton) BodyFromReader(r io.Reader) ResponseOption {
 	return func(config *responseConfig) { config.dataReader = r }
 }
 
 // BodyWithAttachment sets headers to deliver the provided content as a downloaded attachment
-// with Content-Type set dynamically according to the file extension.
+// with Content-Type set dynamically according to the file extension (see BodyFromReader).
 func (responseSingleton) BodyWithAttachment(filename string, content io.Reader) ResponseOption {
 	return func(config *responseConfig) {
 		config.header.Set(headerContentDisposition, fmt.Sprintf(attachmentDisposition, filename))
@@ -134,38 +236,282 @@ func (responseSingleton) BodyWithAttachment(filename string, content io.Reader)
 var (
 	headerContentType        = "Content-Type"
 	headerContentDisposition = "Content-Disposition"
+	headerContentLength      = "Content-Length"
+	headerAccept             = "Accept"
+	headerVary               = "Vary"
 
 	attachmentDisposition = `attachment; filename="%s"`
 	jsonContentType       = "application/json; charset=utf-8"
 )
 
 type responseConfig struct {
+	flusher    *Flusher
 	header     http.Header
 	status     int
 	dataReader io.Reader
 	data       bytes.Buffer
-	dataJSON   any
+	dataValue  any
 	jsonErrors *Errors
+	request    *http.Request
+	negotiate  bool
+	problem    bool
+	written    int64
+	stream     responseStream
+	autoETag   bool
+	uncompress bool
+
+	streamEncoding string // the content coding with which a stream is compressed (see streamWriter)
+
+	errorHandlers []ErrorHandler
+}
+
+// resolveCodec returns the Codec with which errors or the data value will be encoded, setting a Content-Type header
+// if none was set, or replacing the response with ErrInternalServerError if there is no Codec for the Content-Type.
+// Problems (see Response.Problem and ProblemFormat) are labeled with the corresponding problem Content-Type, whereas
+// errors which take precedence over a Problem body (with ErrorsFormat) lose that label.
+func (this *responseConfig) resolveCodec() Codec {
+	codec := this.selectCodec()
+	if len(this.jsonErrors.Errors) > 0 {
+		wasProblem := this.problem
+		this.problem = this.flusher.errorFormat == ProblemFormat
+		if wasProblem && !this.problem {
+			setHeader(this.header, headerContentType, codec.MediaType())
+		}
+	}
+	if this.problem {
+		setHeader(this.header, headerContentType, problemContentType(codec))
+	}
+	return codec
+}
+func (this *responseConfig) selectCodec() Codec {
+	if this.negotiate && this.request != nil {
+		return this.negotiateCodec()
+	}
+	if len(this.jsonErrors.Errors) > 0 {
+		return this.flusher.jsonCodec()
+	}
+	if this.dataValue == nil {
+		return nil
+	}
+	contentType := this.header.Get(headerContentType)
+	if contentType == "" {
+		setHeader(this.header, headerContentType, this.flusher.jsonContentType())
+		return this.flusher.jsonCodec()
+	}
+	if codec, ok := DefaultCodecs.Lookup(contentType); ok {
+		return this.flusher.codec(codec)
+	}
+	this.status = http.StatusInternalServerError
+	setHeader(this.header, headerContentType, this.flusher.jsonContentType())
+	this.jsonErrors.Append(ErrInternalServerError)
+	return this.flusher.jsonCodec()
+}
+
+func (this *responseConfig) negotiateCodec() Codec {
+	if len(this.jsonErrors.Errors) == 0 && this.dataValue == nil {
+		return nil
+	}
+	addVary(this.header, headerAccept)
+	codec, ok := DefaultCodecs.Negotiate(this.request.Header.Values(headerAccept)...)
+	if !ok && len(this.jsonErrors.Errors) == 0 {
+		this.status = http.StatusNotAcceptable
+		this.jsonErrors.Append(ErrNotAcceptable)
+	}
+	if !ok {
+		codec = this.flusher.jsonCodec()
+	}
+	codec = this.flusher.codec(codec)
+	setHeader(this.header, headerContentType, codec.MediaType())
+	return codec
+}
+
+// errorsBody returns the value with which errors are serialized, according to the ErrorFormat.
+func (this *responseConfig) errorsBody() any {
+	if this.problem {
+		return newErrorsProblem(this.status, this.jsonErrors.Errors)
+	}
+	return this.jsonErrors
+}
+
+func (this *responseConfig) flush(response http.ResponseWriter) (err error) {
+	switch streaming := this.stream != nil && len(this.jsonErrors.Errors) == 0; {
+	case streaming && this.notModified():
+		err = this.writeNotModified(response)
+	case streaming:
+		err = this.writeStream(response)
+	default:
+		err = this.writeBody(response)
+	}
+	this.report(err)
+	this.flusher.observe(FlushEvent{Request: this.request, Status: this.status, Header: this.header, Written: this.written, Err: err})
+	return err
+}
+func (this *responseConfig) writeBody(response http.ResponseWriter) (err error) {
+	err = this.encodeBody(this.resolveCodec())
+	this.tagBody()
+	if this.notModified() {
+		return errors.Join(err, this.writeNotModified(response))
+	}
+
+	if this.dataReader == nil && this.data.Len() > 0 {
+		this.compressBody()
+		this.setContentLength()
+	}
+	if seeker, ok := this.dataReader.(io.ReadSeeker); ok && this.status == http.StatusOK {
+		return this.writeSeekable(response, seeker)
+	}
+	if this.dataReader != nil {
+		return this.writeReader(response, this.dataReader, -1)
+	}
+	response.WriteHeader(this.status)
+
+	if this.data.Len() > 0 {
+		err = errors.Join(err, this.writeFromReader(response, &this.data))
+	}
+	return err
+}
+
+// responseStream writes the body incrementally, once the status code and headers have been written, counting what
+// it writes in the *responseConfig (see write).
+type responseStream interface {
+	prepare(config *responseConfig)
+	write(config *responseConfig, response http.ResponseWriter) error
+}
+
+func (this *responseConfig) writeStream(response http.ResponseWriter) error {
+	this.header.Del(headerContentLength)
+	this.stream.prepare(this)
+	if baseMediaType(this.header.Get(headerContentType)) != eventStreamContentType { // events mustn't wait on a compressor
+		if this.streamEncoding = this.selectEncoding(-1); this.streamEncoding != "" {
+			this.markEncoded(this.streamEncoding)
+		}
+	}
+	response.WriteHeader(this.status)
+	if err := this.stream.write(this, response); err != nil {
+		return fmt.Errorf("%w: %w", ErrResponseStream, err)
+	}
+	return nil
+}
+
+// write writes p to the ResponseWriter on behalf of a responseStream.
+func (this *responseConfig) write(response http.ResponseWriter, p []byte) error {
+	written, err := response.Write(p)
+	this.written += int64(written)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrResponseCopy, err)
+	}
+	return nil
+}
+
+// setHeader is like http.Header.Set, but doesn't allocate when the header already has the value (ie. when the
+// ResponseWriter, or at least its header, is reused).
+func setHeader(header http.Header, key, value string) {
+	if values := header[key]; len(values) == 1 && values[0] == value {
+		return
+	}
+	header.Set(key, value)
 }
 
-func (this *responseConfig) writeFromReader(response http.ResponseWriter, reader io.Reader) {
+// addVary adds the provided header name to the Vary header, unless already present.
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values(headerVary) {
+		for existing := range strings.SplitSeq(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), name) {
+				return
+			}
+		}
+	}
+	header.Add(headerVary, name)
+}
+
+// encodeBody serializes the errors, or else the data value, into the data buffer (which takes precedence over any
+// reader) before anything is committed to the ResponseWriter, so that a failure to encode can still be answered
+// with ErrInternalServerError and 500 Internal Server Error rather than a partial body.
+func (this *responseConfig) encodeBody(codec Codec) error {
+	if len(this.jsonErrors.Errors) == 0 && this.dataValue == nil {
+		return nil
+	}
+	this.dataReader = nil
+	this.data.Reset()
+	value := this.dataValue
+	if len(this.jsonErrors.Errors) > 0 {
+		value = this.errorsBody()
+	} else if problem, ok := value.(Problem); ok && this.problem {
+		value = completeProblem(problem, this.status)
+	}
+	err := codec.Encode(&this.data, value)
+	if err == nil {
+		return nil
+	}
+	this.data.Reset()
+	this.status = http.StatusInternalServerError
+	this.jsonErrors.Errors = append(this.jsonErrors.Errors[:0], ErrInternalServerError)
+	this.problem = this.flusher.errorFormat == ProblemFormat
+	codec = this.flusher.jsonCodec()
+	setHeader(this.header, headerContentType, codec.MediaType())
+	if this.problem {
+		setHeader(this.header, headerContentType, problemContentType(codec))
+	}
+	if retryErr := codec.Encode(&this.data, this.errorsBody()); retryErr != nil {
+		this.data.Reset()
+	}
+	return fmt.Errorf("%w: %w", ErrResponseEncode, err)
+}
+func (this *responseConfig) setContentLength() {
+	var buffer [20]byte
+	length := strconv.AppendInt(buffer[:0], int64(this.data.Len()), 10)
+	if values := this.header[headerContentLength]; len(values) == 1 && values[0] == string(length) {
+		return
+	}
+	this.header.Set(headerContentLength, string(length))
+}
+func (this *responseConfig) writeFromReader(response http.ResponseWriter, reader io.Reader) (err error) {
+	defer func() { err = errors.Join(err, closeReader(reader)) }()
+	written, copyErr := io.Copy(response, reader)
+	this.written += written
+	if copyErr != nil {
+		return fmt.Errorf("%w: %w", ErrResponseCopy, copyErr)
+	}
+	return nil
+}
+
+// closeReader calls Close() on the reader (if implemented), returning any error wrapped with ErrResponseClose.
+func closeReader(reader io.Reader) error {
 	if closer, ok := reader.(io.Closer); ok {
-		defer func() { _ = closer.Close() }()
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("%w: %w", ErrResponseClose, err)
+		}
+	}
+	return nil
+}
+func (this *responseConfig) report(err error) {
+	if err == nil || len(this.errorHandlers) == 0 {
+		return
+	}
+	ctx := context.Background()
+	if this.request != nil {
+		ctx = this.request.Context()
+	}
+	for _, handler := range this.errorHandlers {
+		handler(ctx, err)
 	}
-	_, _ = io.Copy(response, reader)
 }
 
-func (this *responseConfig) reset(header http.Header) {
+func (this *responseConfig) reset(flusher *Flusher, header http.Header) {
+	this.flusher = flusher
 	this.header = header
 	this.status = http.StatusOK
 	this.data.Reset()
-	this.dataJSON = nil
+	this.dataValue = nil
+	this.request = nil
+	this.negotiate = false
+	this.problem = false
+	this.written = 0
+	this.stream = nil
+	this.autoETag = false
+	this.uncompress = false
+	this.streamEncoding = ""
+	this.errorHandlers = this.errorHandlers[:0]
 	this.dataReader = nil
 	this.jsonErrors.Errors = this.jsonErrors.Errors[:0]
 }
-
-var responseConfigs = NewPool[*responseConfig](func() *responseConfig {
-	config := &responseConfig{jsonErrors: NewErrors()}
-	config.reset(nil)
-	return config
-})
@@ -0,0 +1,194 @@
+package scuter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compression compresses the bodies of responses (with gzip or deflate, whichever the Accept-Encoding header of the
+// request prefers, see Response.Request) having at least minBytes and a Content-Type among those provided, each of
+// which may be a wildcard (ie. "text/*"). Without any content types, JSON, XML, NDJSON, JavaScript, SVG, and text
+// are compressed. Such responses gain 'Accept-Encoding' in their Vary header, and their ETag (if any) is made weak,
+// as the compressed bytes differ from those it describes, and they no longer advertise 'Accept-Ranges', as any
+// range would refer to the uncompressed bytes. Streamed values (see NDJSONBody and JSONArrayBody) are compressed as
+// they're written, and the compressor is flushed whenever the stream is. Event streams (see Response.EventStream),
+// whose events mustn't be held back, bodies which already have a Content-Encoding header, bodies which answer a
+// Range request, and those which opt out (see Response.Uncompressed) are sent as is.
+func (flushingSingleton) Compression(minBytes int, contentTypes ...string) FlusherOption {
+	return func(this *Flusher) {
+		if len(contentTypes) == 0 {
+			contentTypes = defaultCompressibleTypes
+		}
+		this.compression = &compressionPolicy{minBytes: int64(minBytes), contentTypes: contentTypes}
+	}
+}
+
+// Uncompressed opts the response out of compression (see Flushing.Compression), which is appropriate for content
+// that is already compressed (ie. an archive or image supplied to Response.BodyWithAttachment).
+func (responseSingleton) Uncompressed() ResponseOption {
+	return func(config *responseConfig) { config.uncompress = true }
+}
+
+type compressionPolicy struct {
+	minBytes     int64
+	contentTypes []string
+}
+
+// selectEncoding returns the content coding with which a body of the provided size (or -1 if unknown) is to be
+// compressed, if any, adding 'Accept-Encoding' to the Vary header whenever the response is eligible for compression
+// (whether or not the client accepts it), so that caches store the compressed and uncompressed responses separately.
+func (this *responseConfig) selectEncoding(size int64) string {
+	policy := this.flusher.compression
+	if policy == nil || this.uncompress || this.request == nil || this.header.Get(headerContentEncoding) != "" {
+		return ""
+	}
+	if size >= 0 && size < policy.minBytes {
+		return ""
+	}
+	if !matchesMediaType(policy.contentTypes, baseMediaType(this.header.Get(headerContentType))) {
+		return ""
+	}
+	addVary(this.header, headerAcceptEncoding)
+	return negotiateEncoding(this.request.Header.Values(headerAcceptEncoding))
+}
+
+// compressBody compresses the data buffer, when called for and worthwhile.
+func (this *responseConfig) compressBody() {
+	encoding := this.selectEncoding(int64(this.data.Len()))
+	if encoding == "" {
+		return
+	}
+	buffer := compressionBuffers.Get()
+	defer compressionBuffers.Put(buffer)
+	buffer.Reset()
+	pool := compressors[encoding]
+	compressor := pool.Get()
+	defer releaseCompressor(pool, compressor)
+	compressor.Reset(buffer)
+	_, _ = compressor.Write(this.data.Bytes()) // writes to a bytes.Buffer don't fail (see Close)
+	if err := compressor.Close(); err != nil || buffer.Len() >= this.data.Len() {
+		return
+	}
+	this.data.Reset()
+	_, _ = this.data.Write(buffer.Bytes())
+	this.markEncoded(encoding)
+}
+
+// writeReader writes the content of the reader, which has the provided size (or -1 if unknown), compressing it when
+// called for, and calls Close() on the reader (if implemented).
+func (this *responseConfig) writeReader(response http.ResponseWriter, reader io.Reader, size int64) (err error) {
+	encoding := this.selectEncoding(size)
+	if encoding == "" {
+		if size >= 0 {
+			this.header.Set(headerContentLength, strconv.FormatInt(size, 10))
+		}
+		response.WriteHeader(this.status)
+		return this.writeFromReader(response, reader)
+	}
+	defer func() { err = errors.Join(err, closeReader(reader)) }()
+	this.header.Del(headerContentLength)
+	this.header.Del(headerAcceptRanges) // ranges refer to the uncompressed content (see writeSeekable)
+	this.markEncoded(encoding)
+	response.WriteHeader(this.status)
+	pool := compressors[encoding]
+	compressor := pool.Get()
+	defer releaseCompressor(pool, compressor)
+	compressor.Reset(bodyWriter{config: this, response: response})
+	_, err = io.Copy(compressor, reader)
+	if err = errors.Join(err, compressor.Close()); err != nil {
+		return fmt.Errorf("%w: %w", ErrResponseCopy, err)
+	}
+	return nil
+}
+
+// releaseCompressor returns the compressor to the pool, first detaching it from the writer it wrapped (ie. the
+// ResponseWriter), so that the pool doesn't keep that alive.
+func releaseCompressor(pool *Pool[compressor], compressor compressor) {
+	compressor.Reset(io.Discard)
+	pool.Put(compressor)
+}
+func (this *responseConfig) markEncoded(encoding string) {
+	this.header.Set(headerContentEncoding, encoding)
+	if etag := this.header.Get(headerETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+		this.header.Set(headerETag, "W/"+etag)
+	}
+}
+
+// negotiateEncoding returns the supported content coding most acceptable according to the provided Accept-Encoding
+// header values (ie. "gzip;q=0.8, deflate, *;q=0"), preferring gzip over deflate when equally acceptable, or "" if
+// neither is acceptable. Without an Accept-Encoding header, nothing is compressed.
+func negotiateEncoding(acceptEncoding []string) string {
+	gzipQuality, deflateQuality, anyQuality := -1.0, -1.0, 0.0
+	for _, header := range acceptEncoding {
+		for element := range strings.SplitSeq(header, ",") {
+			coding, parameters, _ := strings.Cut(element, ";")
+			quality := 1.0
+			if key, value, _ := strings.Cut(parameters, "="); strings.EqualFold(strings.TrimSpace(key), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					quality = min(max(parsed, 0), 1)
+				}
+			}
+			switch strings.ToLower(strings.TrimSpace(coding)) {
+			case "gzip", "x-gzip":
+				gzipQuality = quality
+			case "deflate":
+				deflateQuality = quality
+			case "*":
+				anyQuality = quality
+			}
+		}
+	}
+	if gzipQuality < 0 {
+		gzipQuality = anyQuality
+	}
+	if deflateQuality < 0 {
+		deflateQuality = anyQuality
+	}
+	switch {
+	case gzipQuality > 0 && gzipQuality >= deflateQuality:
+		return encodingGzip
+	case deflateQuality > 0:
+		return encodingDeflate
+	}
+	return ""
+}
+
+// compressor is implemented by *gzip.Writer and *zlib.Writer, which are pooled and reset for each use.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+var (
+	compressors = map[string]*Pool[compressor]{
+		encodingGzip:    NewPool(func() compressor { return gzip.NewWriter(nil) }),
+		encodingDeflate: NewPool(func() compressor { return zlib.NewWriter(nil) }),
+	}
+	compressionBuffers = NewPool(func() *bytes.Buffer { return new(bytes.Buffer) })
+
+	defaultCompressibleTypes = []string{
+		"application/json",
+		"application/problem+json",
+		"application/x-ndjson",
+		"application/xml",
+		"application/problem+xml",
+		"application/javascript",
+		"image/svg+xml",
+		"text/*",
+	}
+
+	headerAcceptEncoding = "Accept-Encoding"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate" // which, in HTTP, refers to the zlib format (RFC 9110, section 8.4.1.2)
+)
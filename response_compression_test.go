@@ -0,0 +1,202 @@
+package scuter
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+var compressibleText = strings.Repeat("compressible ", 100)
+
+func compressionRequest(acceptEncoding string) *http.Request {
+	return NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Accept-Encoding", acceptEncoding))
+}
+func decompress(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+	var reader io.Reader
+	var err error
+	if encoding == "gzip" {
+		reader, err = gzip.NewReader(body)
+	} else {
+		reader, err = zlib.NewReader(body)
+	}
+	should.So(t, err, should.BeNil)
+	data, err := io.ReadAll(reader)
+	should.So(t, err, should.BeNil)
+	return string(data)
+}
+
+func TestFlusherCompression_JSONBody(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(100))
+	for acceptEncoding, expected := range map[string]string{
+		"gzip":                      "gzip",
+		"deflate, gzip":             "gzip",
+		"deflate":                   "deflate",
+		"gzip;q=0.5, deflate;q=0.8": "deflate",
+		"*":                         "gzip",
+		"br, *;q=0.1, gzip;q=0":     "deflate",
+		"x-gzip":                    "gzip",
+		"GZIP;Q=1":                  "gzip",
+		"identity, gzip;q=0, *;q=0": "",
+		"br":                        "",
+		"":                          "",
+	} {
+		recorder := httptest.NewRecorder()
+
+		err := flusher.FlushErr(recorder, Response.Request(compressionRequest(acceptEncoding)),
+			Response.ETag("v1", false), Response.JSONBody(compressibleText))
+
+		should.So(t, err, should.BeNil)
+		should.So(t, recorder.Header().Get("Vary"), should.Equal, "Accept-Encoding")
+		should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, expected)
+		should.So(t, recorder.Header().Get("Content-Length"), should.Equal, strconv.Itoa(recorder.Body.Len()))
+		if expected == "" {
+			should.So(t, recorder.Header().Get("ETag"), should.Equal, `"v1"`)
+			should.So(t, recorder.Body.String(), should.Equal, `"`+compressibleText+`"`+"\n")
+		} else {
+			should.So(t, recorder.Header().Get("ETag"), should.Equal, `W/"v1"`)
+			should.So(t, recorder.Body.Len() < len(compressibleText), should.BeTrue)
+			should.So(t, decompress(t, expected, recorder.Body), should.Equal, `"`+compressibleText+`"`+"\n")
+		}
+	}
+}
+func TestFlusherCompression_NotCompressed(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(100, "text/*"))
+	request := compressionRequest("gzip")
+	for name, options := range map[string][]ResponseOption{
+		"below threshold":     {Response.ContentType("text/plain"), Response.BytesBody([]byte("short"))},
+		"disallowed type":     {Response.JSONBody(compressibleText)},
+		"opted out":           {Response.Uncompressed(), Response.ContentType("text/plain"), Response.BytesBody([]byte(compressibleText))},
+		"already encoded":     {Response.Header("Content-Encoding", "br"), Response.ContentType("text/plain"), Response.BytesBody([]byte(compressibleText))},
+		"without the request": {Response.ContentType("text/plain"), Response.BytesBody([]byte(compressibleText))},
+	} {
+		recorder := httptest.NewRecorder()
+		if name != "without the request" {
+			options = append(options, Response.Request(request))
+		}
+
+		flusher.Flush(recorder, options...)
+
+		should.So(t, recorder.Header().Get("Content-Encoding") == "gzip", should.BeFalse)
+	}
+}
+func TestFlusherCompression_NotWorthwhile(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(0))
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.Request(compressionRequest("gzip")), Response.JSONBody(1))
+
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "")
+	should.So(t, recorder.Body.String(), should.Equal, "1\n")
+}
+func TestFlusherCompression_DefaultFlusherDoesNotCompress(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Request(compressionRequest("gzip")), Response.JSONBody(compressibleText))
+
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "")
+	should.So(t, recorder.Header().Get("Vary"), should.Equal, "")
+}
+func TestFlusherCompression_Reader(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(100))
+	reader := &Closer{Reader: &Reader{Reader: io.MultiReader(strings.NewReader(compressibleText))}}
+	recorder := httptest.NewRecorder()
+
+	err := flusher.FlushErr(recorder, Response.Request(compressionRequest("gzip")),
+		Response.ContentType("text/csv"), Response.BodyFromReader(reader))
+
+	should.So(t, err, should.BeNil)
+	should.So(t, reader.closed, should.Equal, 1)
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "gzip")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "")
+	should.So(t, decompress(t, "gzip", recorder.Body), should.Equal, compressibleText)
+}
+func TestFlusherCompression_SeekableReader(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(100))
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.Request(compressionRequest("deflate")),
+		Response.ContentType("text/plain"), Response.BodyFromReader(strings.NewReader(compressibleText)))
+
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "deflate")
+	should.So(t, recorder.Header().Get("Accept-Ranges"), should.Equal, "")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "")
+	should.So(t, decompress(t, "deflate", recorder.Body), should.Equal, compressibleText)
+}
+func TestFlusherCompression_RangeNotCompressed(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(0))
+	request := compressionRequest("gzip")
+	request.Header.Set("Range", "bytes=0-11")
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.Request(request),
+		Response.ContentType("text/plain"), Response.BodyFromReader(strings.NewReader(compressibleText)))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusPartialContent)
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "")
+	should.So(t, recorder.Body.String(), should.Equal, "compressible")
+}
+func TestFlusherCompression_AttachmentOptOut(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(0, "*/*"))
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, Response.Request(compressionRequest("gzip")), Response.Uncompressed(),
+		Response.BodyWithAttachment("archive.zip", strings.NewReader(compressibleText)))
+
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "")
+	should.So(t, recorder.Body.String(), should.Equal, compressibleText)
+
+	compressed := httptest.NewRecorder()
+	flusher.Flush(compressed, Response.Request(compressionRequest("gzip")),
+		Response.BodyWithAttachment("report.csv", strings.NewReader(compressibleText)))
+	should.So(t, compressed.Header().Get("Content-Encoding"), should.Equal, "gzip")
+}
+func TestFlusherCompression_NDJSONStream(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(1000))
+	recorder := httptest.NewRecorder()
+	var expected strings.Builder
+	for x := range 100 {
+		expected.WriteString(`{"id":` + strconv.Itoa(x+1) + "}\n")
+	}
+
+	err := flusher.FlushErr(recorder, NDJSONBodyErr(compressionRequest("gzip"), streamModels(100, nil)))
+
+	should.So(t, err, should.BeNil)
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "gzip")
+	should.So(t, recorder.Header().Get("Vary"), should.Equal, "Accept-Encoding")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "")
+	should.So(t, recorder.Flushed, should.BeTrue)
+	should.So(t, decompress(t, "gzip", recorder.Body), should.Equal, expected.String())
+}
+func TestFlusherCompression_JSONArrayStreamFailure(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(0))
+	recorder := httptest.NewRecorder()
+
+	err := flusher.FlushErr(recorder, JSONArrayBodyErr(compressionRequest("deflate"), streamModels(2, errors.New("boink"))))
+
+	should.So(t, errors.Is(err, ErrResponseStream), should.BeTrue)
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "deflate")
+	should.So(t, decompress(t, "deflate", recorder.Body), should.Equal, `[{"id":1},{"id":2}`)
+}
+func TestFlusherCompression_EventStreamNotCompressed(t *testing.T) {
+	flusher := NewFlusher(Flushing.Compression(0))
+	request := compressionRequest("gzip")
+	recorder := httptest.NewRecorder()
+
+	_ = flusher.FlushErr(recorder, Response.EventStream(request, func(events *EventWriter) error {
+		return events.Send(Event{Data: compressibleText})
+	}))
+
+	should.So(t, recorder.Header().Get("Content-Encoding"), should.Equal, "")
+	should.So(t, recorder.Header().Get("Vary"), should.Equal, "")
+	should.So(t, recorder.Body.String(), should.Equal, "data: "+compressibleText+"\n\n")
+}
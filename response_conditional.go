@@ -0,0 +1,144 @@
+package scuter
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag sets the 'ETag' header to the provided value, quoted (and prefixed with "W/" when weak), ie. `"v42"` or
+// `W/"v42"`. When the response is associated with a GET or HEAD request (see Response.Request) whose If-None-Match
+// header matches the ETag, Flush answers with 304 Not Modified instead of the body.
+func (responseSingleton) ETag(value string, weak bool) ResponseOption {
+	value = `"` + value + `"`
+	if weak {
+		value = "W/" + value
+	}
+	return func(config *responseConfig) { config.header.Set(headerETag, value) }
+}
+
+// AutoETag sets the 'ETag' header (unless already set, see Response.ETag) to a hash of the encoded body, which is
+// only possible for successful responses whose body is not read from an io.Reader or streamed. Hashing requires the
+// body to be encoded even when the client's copy is current, so an ETag derived from a version number or timestamp
+// (see Response.ETag) is cheaper, where available.
+func (responseSingleton) AutoETag() ResponseOption {
+	return func(config *responseConfig) { config.autoETag = true }
+}
+
+// LastModified sets the 'Last-Modified' header to the provided time (in UTC, truncated to the second). When the
+// response is associated with a GET or HEAD request (see Response.Request) whose If-Modified-Since header is not
+// before that time, Flush answers with 304 Not Modified instead of the body (unless the request also has an
+// If-None-Match header, which takes precedence, see Response.ETag).
+func (responseSingleton) LastModified(t time.Time) ResponseOption {
+	modified := t.UTC().Format(http.TimeFormat)
+	return func(config *responseConfig) { config.header.Set(headerLastModified, modified) }
+}
+
+// tagBody sets the ETag header to a hash of the encoded body, when called for by Response.AutoETag.
+func (this *responseConfig) tagBody() {
+	if !this.autoETag || this.dataReader != nil || len(this.jsonErrors.Errors) > 0 {
+		return
+	}
+	if this.status < 200 || this.status > 299 || this.header.Get(headerETag) != "" {
+		return
+	}
+	sum := sha256.Sum256(this.data.Bytes())
+	var buffer [2 + (autoETagBytes*8+5)/6]byte
+	buffer[0] = '"'
+	base64.RawURLEncoding.Encode(buffer[1:], sum[:autoETagBytes])
+	buffer[len(buffer)-1] = '"'
+	this.header.Set(headerETag, string(buffer[:]))
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since headers of the request against the ETag and
+// Last-Modified headers of the response (RFC 9110, section 13.2.2), reporting whether the client's copy is current.
+func (this *responseConfig) notModified() bool {
+	if this.request == nil || this.status != http.StatusOK || len(this.jsonErrors.Errors) > 0 {
+		return false
+	}
+	if method := this.request.Method; method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	if conditions := this.request.Header.Values(headerIfNoneMatch); len(conditions) > 0 {
+		return matchETag(conditions, this.header.Get(headerETag), false)
+	}
+	since, err := http.ParseTime(this.request.Header.Get(headerIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(this.header.Get(headerLastModified))
+	return err == nil && !modified.After(since)
+}
+
+// writeNotModified answers with 304 Not Modified, omitting the body and the headers which describe it.
+func (this *responseConfig) writeNotModified(response http.ResponseWriter) error {
+	this.status = http.StatusNotModified
+	this.header.Del(headerContentType)
+	this.header.Del(headerContentLength)
+	this.header.Del(headerContentEncoding)
+	if this.header.Get(headerETag) != "" {
+		this.header.Del(headerLastModified)
+	}
+	this.data.Reset()
+	response.WriteHeader(this.status)
+	return closeReader(this.dataReader)
+}
+
+// matchETag reports whether any of the entity tags listed in the values of a conditional header (ie. If-None-Match)
+// match the etag, where "*" matches any etag. The weak comparison ignores the "W/" prefix whereas the strong
+// comparison requires that neither be weak (RFC 9110, section 8.8.3.2).
+func matchETag(values []string, etag string, strong bool) bool {
+	for _, value := range values {
+		for value = strings.TrimLeft(value, " \t,"); value != ""; value = strings.TrimLeft(value, " \t,") {
+			if value[0] == '*' {
+				return true
+			}
+			candidate, rest, ok := scanETag(value)
+			if !ok {
+				break
+			}
+			if etag != "" && compareETags(candidate, etag, strong) {
+				return true
+			}
+			value = rest
+		}
+	}
+	return false
+}
+func compareETags(a, b string, strong bool) bool {
+	if strong {
+		return a == b && !strings.HasPrefix(a, "W/")
+	}
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// scanETag splits the leading entity tag (ie. `"v42"` or `W/"v42"`) from the rest of s.
+func scanETag(s string) (etag, rest string, ok bool) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s) < start+2 || s[start] != '"' {
+		return "", "", false
+	}
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", "", false
+	}
+	end += start + 2
+	return s[:end], s[end:], true
+}
+
+var (
+	headerETag            = "ETag"
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+	headerContentEncoding = "Content-Encoding"
+)
+
+// autoETagBytes is the number of bytes of the hash of the body used by AutoETag, which encode (without padding) to
+// 24 characters.
+const autoETagBytes = 18
@@ -0,0 +1,160 @@
+package scuter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestResponseETag(t *testing.T) {
+	strong, weak := httptest.NewRecorder(), httptest.NewRecorder()
+
+	Flush(strong, Response.ETag("v42", false), Response.JSONBody(42))
+	Flush(weak, Response.ETag("v42", true), Response.JSONBody(42))
+
+	should.So(t, strong.Header().Get("ETag"), should.Equal, `"v42"`)
+	should.So(t, weak.Header().Get("ETag"), should.Equal, `W/"v42"`)
+	should.So(t, strong.Body.String(), should.Equal, "42\n")
+}
+func TestResponseETag_NotModified(t *testing.T) {
+	for _, condition := range []string{`"v42"`, `W/"v42"`, `"v1", W/"v42"`, `"a,b" , "v42"`, `*`} {
+		request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("If-None-Match", condition))
+		recorder := httptest.NewRecorder()
+
+		Flush(recorder, Response.Request(request), Response.ETag("v42", false), Response.JSONBody(42),
+			Response.LastModified(time.Now()), Response.Header("Cache-Control", "max-age=60"))
+
+		should.So(t, recorder.Code, should.Equal, http.StatusNotModified)
+		should.So(t, recorder.Body.String(), should.Equal, "")
+		should.So(t, recorder.Header().Get("ETag"), should.Equal, `"v42"`)
+		should.So(t, recorder.Header().Get("Cache-Control"), should.Equal, "max-age=60")
+		should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "")
+		should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "")
+		should.So(t, recorder.Header().Get("Last-Modified"), should.Equal, "")
+	}
+}
+func TestResponseETag_Modified(t *testing.T) {
+	for _, condition := range []string{`"v41"`, `v42`, `"v41", "v4`, ``} {
+		request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("If-None-Match", condition))
+		recorder := httptest.NewRecorder()
+
+		Flush(recorder, Response.Request(request), Response.ETag("v42", false), Response.JSONBody(42))
+
+		should.So(t, recorder.Code, should.Equal, http.StatusOK)
+		should.So(t, recorder.Body.String(), should.Equal, "42\n")
+	}
+}
+func TestResponseETag_OnlyGetAndHeadAndSuccess(t *testing.T) {
+	post := NewTestRequest(context.Background(), http.MethodPost, "/", Request.Header("If-None-Match", `"v42"`))
+	head := NewTestRequest(context.Background(), http.MethodHead, "/", Request.Header("If-None-Match", `"v42"`))
+	postRecorder, headRecorder, failureRecorder := httptest.NewRecorder(), httptest.NewRecorder(), httptest.NewRecorder()
+
+	Flush(postRecorder, Response.Request(post), Response.ETag("v42", false), Response.JSONBody(42))
+	Flush(headRecorder, Response.Request(head), Response.ETag("v42", false), Response.JSONBody(42))
+	Flush(failureRecorder, Response.Request(head), Response.ETag("v42", false), Response.JSONErrors(http.StatusNotFound, Error{}))
+
+	should.So(t, postRecorder.Code, should.Equal, http.StatusOK)
+	should.So(t, headRecorder.Code, should.Equal, http.StatusNotModified)
+	should.So(t, failureRecorder.Code, should.Equal, http.StatusNotFound)
+}
+func TestResponseAutoETag(t *testing.T) {
+	first, second, different := httptest.NewRecorder(), httptest.NewRecorder(), httptest.NewRecorder()
+
+	Flush(first, Response.AutoETag(), Response.JSONBody([]int{1, 2}))
+	Flush(second, Response.JSONBody([]int{1, 2}), Response.AutoETag())
+	Flush(different, Response.AutoETag(), Response.JSONBody([]int{2, 1}))
+
+	etag := first.Header().Get("ETag")
+	should.So(t, len(etag), should.Equal, 26)
+	should.So(t, strings.Trim(etag, `"`), should.Equal, etag[1:25])
+	should.So(t, second.Header().Get("ETag"), should.Equal, etag)
+	should.So(t, different.Header().Get("ETag") == etag, should.BeFalse)
+
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("If-None-Match", etag))
+	recorder := httptest.NewRecorder()
+	Flush(recorder, Response.Request(request), Response.AutoETag(), Response.JSONBody([]int{1, 2}))
+	should.So(t, recorder.Code, should.Equal, http.StatusNotModified)
+	should.So(t, recorder.Header().Get("ETag"), should.Equal, etag)
+}
+func TestResponseAutoETag_Skipped(t *testing.T) {
+	explicit, failure, reader := httptest.NewRecorder(), httptest.NewRecorder(), httptest.NewRecorder()
+
+	Flush(explicit, Response.AutoETag(), Response.ETag("v1", true), Response.JSONBody(1))
+	Flush(failure, Response.AutoETag(), Response.JSONErrors(http.StatusBadRequest, Error{}))
+	Flush(reader, Response.AutoETag(), Response.BodyFromReader(strings.NewReader("data")))
+
+	should.So(t, explicit.Header().Get("ETag"), should.Equal, `W/"v1"`)
+	should.So(t, failure.Header().Get("ETag"), should.Equal, "")
+	should.So(t, reader.Header().Get("ETag"), should.Equal, "")
+}
+func TestResponseLastModified(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 7, 8, 9, 500, time.FixedZone("MDT", -6*60*60))
+	for condition, expected := range map[string]int{
+		"Mon, 06 May 2024 13:08:09 GMT": http.StatusNotModified,
+		"Mon, 06 May 2024 13:08:10 GMT": http.StatusNotModified,
+		"Mon, 06 May 2024 13:08:08 GMT": http.StatusOK,
+		"not a date":                    http.StatusOK,
+	} {
+		request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("If-Modified-Since", condition))
+		recorder := httptest.NewRecorder()
+
+		Flush(recorder, Response.Request(request), Response.LastModified(modified), Response.JSONBody(42))
+
+		should.So(t, recorder.Code, should.Equal, expected)
+		should.So(t, recorder.Header().Get("Last-Modified"), should.Equal, "Mon, 06 May 2024 13:08:09 GMT")
+	}
+}
+func TestResponseLastModified_IfNoneMatchTakesPrecedence(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.With(
+		Request.Header("If-None-Match", `"v1"`),
+		Request.Header("If-Modified-Since", "Mon, 06 May 2024 13:08:09 GMT"),
+	))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Request(request), Response.ETag("v2", false),
+		Response.LastModified(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), Response.JSONBody(42))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+}
+func TestResponseETag_NotModifiedClosesReader(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("If-None-Match", `"v1"`))
+	closeErr := errors.New("close error")
+	reader := &Closer{Reader: &Reader{Reader: strings.NewReader("data")}, closeErr: closeErr}
+	recorder := httptest.NewRecorder()
+
+	err := FlushErr(recorder, Response.Request(request), Response.ETag("v1", false), Response.BodyFromReader(reader))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusNotModified)
+	should.So(t, recorder.Body.String(), should.Equal, "")
+	should.So(t, reader.closed, should.Equal, 1)
+	should.So(t, errors.Is(err, ErrResponseClose), should.BeTrue)
+	should.So(t, errors.Is(err, closeErr), should.BeTrue)
+}
+func TestResponseETag_NotModifiedStream(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("If-None-Match", `"v1"`))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.ETag("v1", false), NDJSONBody(request, func(yield func(int) bool) { yield(1) }))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusNotModified)
+	should.So(t, recorder.Body.String(), should.Equal, "")
+}
+func TestResponseETag_Static(t *testing.T) {
+	static := Response.Static(Response.AutoETag(), Response.JSONBody("constant"))
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+	Flush(recorder, static)
+
+	request.Header.Set("If-None-Match", recorder.Header().Get("ETag"))
+	conditional := httptest.NewRecorder()
+	Flush(conditional, Response.Request(request), static)
+
+	should.So(t, conditional.Code, should.Equal, http.StatusNotModified)
+	should.So(t, conditional.Body.String(), should.Equal, "")
+}
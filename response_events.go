@@ -0,0 +1,214 @@
+package scuter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidEvent = errors.New("scuter: the id and type of an event must not contain newlines")
+
+// Event is a single Server-Sent Event (see EventWriter.Send).
+type Event struct {
+	// ID, when not empty, becomes the last event ID of the client, which it supplies (via the Last-Event-ID header)
+	// upon reconnecting (see EventWriter.LastEventID).
+	ID string
+	// Type, when not empty, names the event (ie. the 'event' field), which otherwise defaults to "message".
+	Type string
+	// Data is the payload of the event, which may span multiple lines.
+	Data string
+	// Retry, when positive, instructs the client how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// EventStream streams Server-Sent Events (ie. text/event-stream) to the client by calling stream, which is expected
+// to send events until it's done or until the request's context is done (see EventWriter.Context), which happens
+// when the client disconnects. A heartbeat comment is sent every 15 seconds (see EventWriter.Heartbeat) to keep
+// the connection from being closed as idle by intermediaries. Any error returned by the stream (other than the
+// cancellation of the request's context) is passed to any ErrorHandler (see Response.OnError) and returned by
+// FlushErr, wrapped with ErrResponseStream.
+func (responseSingleton) EventStream(request *http.Request, stream func(*EventWriter) error) ResponseOption {
+	events := &eventStream{request: request, stream: stream}
+	return func(config *responseConfig) {
+		config.request = request
+		config.stream = events
+	}
+}
+
+type eventStream struct {
+	request *http.Request
+	stream  func(*EventWriter) error
+}
+
+func (this *eventStream) write(config *responseConfig, response http.ResponseWriter) error {
+	writer := newEventWriter(config, response, this.request)
+	defer writer.close()
+	err := this.stream(writer)
+	if errors.Is(err, context.Canceled) && this.request.Context().Err() != nil {
+		return nil // the client went away, which is how most streams end
+	}
+	return err
+}
+
+func (this *eventStream) prepare(config *responseConfig) {
+	config.header.Set(headerContentType, eventStreamContentType)
+	config.header.Set(headerCacheControl, "no-cache")
+	config.header.Set("X-Accel-Buffering", "no") // prevents buffering by proxies such as nginx
+}
+
+// EventWriter sends Server-Sent Events to the client, flushing each one as it's sent. It is only valid until the
+// func supplied to Response.EventStream returns, and is safe for concurrent use.
+type EventWriter struct {
+	lock       sync.Mutex
+	config     *responseConfig
+	response   http.ResponseWriter
+	controller *http.ResponseController
+	request    *http.Request
+	buffer     []byte
+	heartbeat  *time.Ticker
+	closed     bool
+	done       chan struct{}
+	stopped    sync.WaitGroup
+}
+
+func newEventWriter(config *responseConfig, response http.ResponseWriter, request *http.Request) *EventWriter {
+	this := &EventWriter{
+		config:     config,
+		response:   response,
+		controller: http.NewResponseController(response),
+		request:    request,
+		heartbeat:  time.NewTicker(defaultEventHeartbeat),
+		done:       make(chan struct{}),
+	}
+	_ = this.controller.SetWriteDeadline(time.Time{}) // streams outlive any http.Server.WriteTimeout
+	_ = this.controller.Flush()                       // commits the headers, so the client knows the stream is open
+	this.stopped.Add(1)
+	go this.beat()
+	return this
+}
+
+// Context returns the context of the request, which is done when the client disconnects.
+func (this *EventWriter) Context() context.Context { return this.request.Context() }
+
+// LastEventID returns the value of the Last-Event-ID header sent by a reconnecting client, from which the stream
+// should be resumed (see Event.ID).
+func (this *EventWriter) LastEventID() string {
+	return strings.TrimSpace(this.request.Header.Get(headerLastEventID))
+}
+
+// Heartbeat establishes how often a heartbeat comment is sent; a non-positive interval sends none.
+func (this *EventWriter) Heartbeat(interval time.Duration) {
+	if interval > 0 {
+		this.heartbeat.Reset(interval)
+	} else {
+		this.heartbeat.Stop()
+	}
+}
+
+// Send writes the event and flushes it to the client, returning ErrInvalidEvent if its ID or Type contains a
+// newline, or the error of the request's context once it's done.
+func (this *EventWriter) Send(event Event) error {
+	if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Type, "\r\n") {
+		return ErrInvalidEvent
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.buffer = this.buffer[:0]
+	if event.ID != "" {
+		this.buffer = appendEventField(this.buffer, "id", event.ID)
+	}
+	if event.Type != "" {
+		this.buffer = appendEventField(this.buffer, "event", event.Type)
+	}
+	if event.Retry > 0 {
+		this.buffer = strconv.AppendInt(append(this.buffer, "retry: "...), event.Retry.Milliseconds(), 10)
+		this.buffer = append(this.buffer, '\n')
+	}
+	if event.Data != "" || event.Type != "" {
+		this.buffer = appendEventLines(this.buffer, "data", event.Data)
+	}
+	return this.send(append(this.buffer, '\n'))
+}
+
+// Comment writes a comment, which the client ignores, and flushes it to the client.
+func (this *EventWriter) Comment(text string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.buffer = appendEventLines(this.buffer[:0], "", text)
+	return this.send(append(this.buffer, '\n'))
+}
+
+func (this *EventWriter) send(p []byte) error {
+	if this.closed {
+		return ErrResponseStream
+	}
+	if err := this.request.Context().Err(); err != nil {
+		return err
+	}
+	if err := this.config.write(this.response, p); err != nil {
+		return err
+	}
+	if err := this.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (this *EventWriter) beat() {
+	defer this.stopped.Done()
+	for {
+		select {
+		case <-this.heartbeat.C:
+			_ = this.Comment("heartbeat")
+		case <-this.request.Context().Done():
+			return
+		case <-this.done:
+			return
+		}
+	}
+}
+func (this *EventWriter) close() {
+	this.heartbeat.Stop()
+	close(this.done)
+	this.stopped.Wait()
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.closed = true // nothing may be written once Flush returns
+}
+
+// appendEventLines appends a field for each line of the value (ie. "data: line\n"), as a field value cannot span
+// lines. An empty name produces comment lines (ie. ": line\n").
+func appendEventLines(buffer []byte, name, value string) []byte {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	for {
+		index := strings.IndexAny(value, "\r\n")
+		if index < 0 {
+			return appendEventField(buffer, name, value)
+		}
+		buffer = appendEventField(buffer, name, value[:index])
+		value = value[index+1:]
+	}
+}
+func appendEventField(buffer []byte, name, value string) []byte {
+	buffer = append(buffer, name...)
+	buffer = append(buffer, ':')
+	if value != "" {
+		buffer = append(buffer, ' ')
+		buffer = append(buffer, value...)
+	}
+	return append(buffer, '\n')
+}
+
+var (
+	headerCacheControl = "Cache-Control"
+	headerLastEventID  = "Last-Event-ID"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	defaultEventHeartbeat  = 15 * time.Second
+)
@@ -0,0 +1,110 @@
+package scuter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestResponseEventStream(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Last-Event-ID", " 41 "))
+	recorder := httptest.NewRecorder()
+	var lastEventID string
+
+	err := FlushErr(recorder, Response.EventStream(request, func(events *EventWriter) error {
+		lastEventID = events.LastEventID()
+		should.So(t, events.Context(), should.Equal, request.Context())
+		should.So(t, events.Send(Event{ID: "42", Type: "update", Data: "line 1\r\nline 2\rline 3\n", Retry: time.Second}), should.BeNil)
+		should.So(t, events.Send(Event{Data: " padded"}), should.BeNil)
+		should.So(t, events.Send(Event{Type: "ping"}), should.BeNil)
+		should.So(t, events.Send(Event{ID: "43"}), should.BeNil)
+		return events.Comment("multi\nline")
+	}))
+
+	should.So(t, err, should.BeNil)
+	should.So(t, lastEventID, should.Equal, "41")
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Flushed, should.BeTrue)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "text/event-stream")
+	should.So(t, recorder.Header().Get("Cache-Control"), should.Equal, "no-cache")
+	should.So(t, recorder.Body.String(), should.Equal, ""+
+		"id: 42\nevent: update\nretry: 1000\ndata: line 1\ndata: line 2\ndata: line 3\ndata:\n\n"+
+		"data:  padded\n\n"+
+		"event: ping\ndata:\n\n"+
+		"id: 43\n\n"+
+		": multi\n: line\n\n")
+}
+func TestResponseEventStream_InvalidEvent(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+
+	Flush(httptest.NewRecorder(), Response.EventStream(request, func(events *EventWriter) error {
+		should.So(t, events.Send(Event{ID: "4\n2"}), should.Equal, ErrInvalidEvent)
+		should.So(t, events.Send(Event{Type: "a\rb"}), should.Equal, ErrInvalidEvent)
+		return nil
+	}))
+}
+func TestResponseEventStream_Heartbeat(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.EventStream(request, func(events *EventWriter) error {
+		events.Heartbeat(time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		events.Heartbeat(0)
+		return nil
+	}))
+
+	should.So(t, strings.HasPrefix(recorder.Body.String(), ": heartbeat\n\n"), should.BeTrue)
+}
+func TestResponseEventStream_ClientDisconnected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	request := NewTestRequest(ctx, http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+	cancel()
+
+	err := FlushErr(recorder, Response.EventStream(request, func(events *EventWriter) error {
+		return events.Send(Event{Data: "too late"})
+	}))
+
+	should.So(t, err, should.BeNil)
+	should.So(t, recorder.Body.String(), should.Equal, "")
+}
+func TestResponseEventStream_Error(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	streamErr := errors.New("stream error")
+	var handled error
+	var escaped *EventWriter
+
+	err := FlushErr(httptest.NewRecorder(),
+		Response.OnError(func(_ context.Context, err error) { handled = err }),
+		Response.EventStream(request, func(events *EventWriter) error {
+			escaped = events
+			return streamErr
+		}),
+	)
+
+	should.So(t, errors.Is(err, ErrResponseStream), should.BeTrue)
+	should.So(t, errors.Is(err, streamErr), should.BeTrue)
+	should.So(t, handled, should.Equal, err)
+	should.So(t, escaped.Send(Event{Data: "after the fact"}), should.Equal, ErrResponseStream)
+}
+func TestResponseEventStream_ErrorsTakePrecedence(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+	streamed := false
+
+	Flush(recorder,
+		Response.EventStream(request, func(*EventWriter) error { streamed = true; return nil }),
+		Response.JSONErrors(http.StatusUnauthorized, ErrInternalServerError),
+	)
+
+	should.So(t, streamed, should.BeFalse)
+	should.So(t, recorder.Code, should.Equal, http.StatusUnauthorized)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, jsonContentType)
+}
@@ -0,0 +1,213 @@
+package scuter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+var ErrRangeNotSatisfiable = Error{
+	Fields:  []string{"header.Range"},
+	Name:    "range-not-satisfiable",
+	Message: "The range did not overlap the content.",
+}
+
+// writeSeekable writes content which can seek (see Response.BodyFromReader), advertising 'Accept-Ranges: bytes' and
+// honoring the Range and If-Range headers of the request (if any) the way http.ServeContent does: a single range is
+// answered with 206 Partial Content and a Content-Range header, several ranges with 206 Partial Content and a
+// multipart/byteranges body, and ranges which don't overlap the content with ErrRangeNotSatisfiable and 416 Range
+// Not Satisfiable. The content is presumed to span from its start to its end, regardless of its current offset.
+func (this *responseConfig) writeSeekable(response http.ResponseWriter, content io.ReadSeeker) (err error) {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = content.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return this.writeReader(response, content, -1) // the content can't seek after all, so it's copied as is
+	}
+	this.header.Set(headerAcceptRanges, "bytes")
+	ranges, err := this.requestedRanges(size)
+	if err != nil {
+		this.header.Set(headerContentRange, "bytes */"+strconv.FormatInt(size, 10))
+		return this.replaceContent(response, content, http.StatusRequestedRangeNotSatisfiable, ErrRangeNotSatisfiable)
+	}
+	defer func() { err = errors.Join(err, closeReader(content)) }()
+	switch len(ranges) {
+	case 0:
+		return this.writeReader(response, io.LimitReader(content, size), size)
+	case 1:
+		if _, err = content.Seek(ranges[0].start, io.SeekStart); err != nil {
+			err = fmt.Errorf("%w: %w", ErrResponseCopy, err)
+			return errors.Join(err, this.replaceContent(response, nil, http.StatusInternalServerError, ErrInternalServerError))
+		}
+		this.status = http.StatusPartialContent
+		this.header.Set(headerContentRange, ranges[0].contentRange(size))
+		this.header.Set(headerContentLength, strconv.FormatInt(ranges[0].length, 10))
+		response.WriteHeader(this.status)
+		return this.writeFromReader(response, io.LimitReader(content, ranges[0].length))
+	default:
+		return this.writeMultipartRanges(response, content, ranges, size)
+	}
+}
+func (this *responseConfig) writeMultipartRanges(
+	response http.ResponseWriter, content io.ReadSeeker, ranges []byteRange, size int64,
+) error {
+	writer := multipart.NewWriter(bodyWriter{config: this, response: response})
+	partHeader := textproto.MIMEHeader{}
+	if contentType := this.header.Get(headerContentType); contentType != "" {
+		partHeader.Set(headerContentType, contentType)
+	}
+	this.status = http.StatusPartialContent
+	this.header.Set(headerContentType, "multipart/byteranges; boundary="+writer.Boundary())
+	this.header.Del(headerContentLength)
+	response.WriteHeader(this.status)
+	for _, byteRange := range ranges {
+		partHeader.Set(headerContentRange, byteRange.contentRange(size))
+		part, err := writer.CreatePart(partHeader)
+		if err == nil {
+			_, err = content.Seek(byteRange.start, io.SeekStart)
+		}
+		if err == nil {
+			_, err = io.CopyN(part, content, byteRange.length)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrResponseCopy, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrResponseCopy, err)
+	}
+	return nil
+}
+
+// replaceContent closes the content (if any) and answers with the error instead.
+func (this *responseConfig) replaceContent(response http.ResponseWriter, content io.Reader, status int, failure Error) error {
+	this.status = status
+	this.jsonErrors.Append(failure)
+	this.dataReader = nil
+	this.header.Del(headerContentDisposition)
+	setHeader(this.header, headerContentType, this.flusher.jsonContentType())
+	return errors.Join(closeReader(content), this.writeBody(response))
+}
+
+// requestedRanges returns the ranges of the Range header of a GET or HEAD request (unless the If-Range header
+// indicates that the client's copy is stale), an error if they don't overlap the content, or nil if the whole content
+// is called for.
+func (this *responseConfig) requestedRanges(size int64) ([]byteRange, error) {
+	if this.request == nil || (this.request.Method != http.MethodGet && this.request.Method != http.MethodHead) {
+		return nil, nil
+	}
+	header := this.request.Header.Get(headerRange)
+	if header == "" || !this.rangeIsCurrent() {
+		return nil, nil
+	}
+	return parseByteRanges(header, size)
+}
+
+// rangeIsCurrent evaluates the If-Range header, which holds either the ETag (by strong comparison) or the
+// Last-Modified time (by exact match) of the client's copy (RFC 9110, section 13.1.5).
+func (this *responseConfig) rangeIsCurrent() bool {
+	condition := this.request.Header.Get(headerIfRange)
+	if condition == "" {
+		return true
+	}
+	if etag, rest, ok := scanETag(condition); ok && rest == "" {
+		return compareETags(etag, this.header.Get(headerETag), true)
+	}
+	since, err := http.ParseTime(condition)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(this.header.Get(headerLastModified))
+	return err == nil && modified.Equal(since)
+}
+
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+func (this byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", this.start, this.start+this.length-1, size)
+}
+
+// parseByteRanges parses a Range header (ie. "bytes=0-499, 1000-, -500") according to the size of the content,
+// returning nil (which calls for the whole content) if the unit isn't bytes or if the ranges add up to more than
+// the content (which is cheaper to send whole), or an error if the ranges are malformed or don't overlap the content.
+func parseByteRanges(header string, size int64) ([]byteRange, error) {
+	specs, found := strings.CutPrefix(header, "bytes=")
+	if !found {
+		return nil, nil
+	}
+	var ranges []byteRange
+	var total int64
+	for spec := range strings.SplitSeq(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		first, last, found := strings.Cut(spec, "-")
+		if !found {
+			return nil, errMalformedRange
+		}
+		var result byteRange
+		if first == "" { // a suffix (ie. "-500"), which is the last n bytes
+			suffix, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || suffix < 0 {
+				return nil, errMalformedRange
+			}
+			suffix = min(suffix, size)
+			result = byteRange{start: size - suffix, length: suffix}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errMalformedRange
+			}
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, errMalformedRange
+				}
+			}
+			result = byteRange{start: start, length: min(end, size-1) - start + 1}
+		}
+		if result.length <= 0 {
+			continue // doesn't overlap the content
+		}
+		ranges = append(ranges, result)
+		total += result.length
+	}
+	if len(ranges) == 0 {
+		return nil, errMalformedRange
+	}
+	if total > size {
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+// bodyWriter counts what is written to the ResponseWriter in the *responseConfig.
+type bodyWriter struct {
+	config   *responseConfig
+	response http.ResponseWriter
+}
+
+func (this bodyWriter) Write(p []byte) (int, error) {
+	written, err := this.response.Write(p)
+	this.config.written += int64(written)
+	return written, err
+}
+
+var errMalformedRange = errors.New("scuter: malformed or unsatisfiable range")
+
+var (
+	headerAcceptRanges = "Accept-Ranges"
+	headerContentRange = "Content-Range"
+	headerRange        = "Range"
+	headerIfRange      = "If-Range"
+)
@@ -0,0 +1,175 @@
+package scuter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+const rangeContent = "0123456789abcdefghij"
+
+func flushRange(t *testing.T, rangeHeader string, options ...ResponseOption) *httptest.ResponseRecorder {
+	t.Helper()
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Range", rangeHeader))
+	recorder := httptest.NewRecorder()
+	options = append(options, Response.Request(request), Response.BodyFromReader(strings.NewReader(rangeContent)))
+	should.So(t, FlushErr(recorder, options...), should.BeNil)
+	return recorder
+}
+
+func TestResponseBodyFromReader_SeekableWithoutRange(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	reader := strings.NewReader(rangeContent)
+	_, _ = reader.Seek(5, io.SeekStart)
+
+	Flush(recorder, Response.BodyFromReader(reader))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Header().Get("Accept-Ranges"), should.Equal, "bytes")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "20")
+	should.So(t, recorder.Body.String(), should.Equal, rangeContent)
+}
+func TestResponseBodyFromReader_NotSeekable(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Range", "bytes=0-1"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Request(request), Response.BodyFromReader(io.MultiReader(strings.NewReader(rangeContent))))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Header().Get("Accept-Ranges"), should.Equal, "")
+	should.So(t, recorder.Body.String(), should.Equal, rangeContent)
+}
+func TestResponseBodyFromReader_SingleRange(t *testing.T) {
+	for header, expected := range map[string]struct{ contentRange, body string }{
+		"bytes=0-4":      {"bytes 0-4/20", "01234"},
+		"bytes=15-":      {"bytes 15-19/20", "fghij"},
+		"bytes=-3":       {"bytes 17-19/20", "hij"},
+		"bytes=-50":      {"bytes 0-19/20", rangeContent},
+		"bytes=18-100":   {"bytes 18-19/20", "ij"},
+		"bytes=30-, 2-3": {"bytes 2-3/20", "23"},
+	} {
+		recorder := flushRange(t, header)
+
+		should.So(t, recorder.Code, should.Equal, http.StatusPartialContent)
+		should.So(t, recorder.Header().Get("Content-Range"), should.Equal, expected.contentRange)
+		should.So(t, recorder.Header().Get("Content-Length"), should.Equal, strconv.Itoa(len(expected.body)))
+		should.So(t, recorder.Body.String(), should.Equal, expected.body)
+	}
+}
+func TestResponseBodyFromReader_HeadRange(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodHead, "/", Request.Header("Range", "bytes=0-1"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Request(request), Response.BodyFromReader(strings.NewReader(rangeContent)))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusPartialContent)
+	should.So(t, recorder.Header().Get("Content-Range"), should.Equal, "bytes 0-1/20")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "2")
+}
+func TestResponseBodyFromReader_MultipleRanges(t *testing.T) {
+	recorder := flushRange(t, "bytes=0-1, 10-12", Response.ContentType("text/plain"))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusPartialContent)
+	mediaType, params, _ := mime.ParseMediaType(recorder.Header().Get("Content-Type"))
+	should.So(t, mediaType, should.Equal, "multipart/byteranges")
+	reader := multipart.NewReader(recorder.Body, params["boundary"])
+	var contentRanges, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		should.So(t, part.Header.Get("Content-Type"), should.Equal, "text/plain")
+		body, _ := io.ReadAll(part)
+		contentRanges = append(contentRanges, part.Header.Get("Content-Range"))
+		bodies = append(bodies, string(body))
+	}
+	should.So(t, contentRanges, should.Equal, []string{"bytes 0-1/20", "bytes 10-12/20"})
+	should.So(t, bodies, should.Equal, []string{"01", "abc"})
+}
+func TestResponseBodyFromReader_Unsatisfiable(t *testing.T) {
+	for _, header := range []string{"bytes=20-", "bytes=5-2", "bytes=x-y", "bytes=-0", "bytes=7"} {
+		request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Range", header))
+		reader := &Closer{Reader: &Reader{Reader: strings.NewReader(rangeContent)}}
+		recorder := httptest.NewRecorder()
+
+		Flush(recorder, Response.Request(request),
+			Response.BodyWithAttachment("file.txt", struct {
+				io.ReadSeeker
+				io.Closer
+			}{strings.NewReader(rangeContent), reader}))
+
+		should.So(t, recorder.Code, should.Equal, http.StatusRequestedRangeNotSatisfiable)
+		should.So(t, recorder.Header().Get("Content-Range"), should.Equal, "bytes */20")
+		should.So(t, recorder.Header().Get("Content-Disposition"), should.Equal, "")
+		should.So(t, recorder.Header().Get("Content-Type"), should.Equal, jsonContentType)
+		should.So(t, recorder.Body.String(), should.Equal, `{"errors":[{"fields":["header.Range"],"name":"range-not-satisfiable","message":"The range did not overlap the content."}]}`+"\n")
+		should.So(t, reader.closed, should.Equal, 1)
+	}
+}
+func TestResponseBodyFromReader_RangesIgnored(t *testing.T) {
+	for _, header := range []string{"items=0-1", "bytes=0-15, 5-19"} {
+		recorder := flushRange(t, header)
+
+		should.So(t, recorder.Code, should.Equal, http.StatusOK)
+		should.So(t, recorder.Body.String(), should.Equal, rangeContent)
+	}
+}
+func TestResponseBodyFromReader_RangeOnlyForGet(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodPost, "/", Request.Header("Range", "bytes=0-1"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.Request(request), Response.BodyFromReader(strings.NewReader(rangeContent)))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Body.String(), should.Equal, rangeContent)
+}
+func TestResponseBodyFromReader_IfRange(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 13, 8, 9, 0, time.UTC)
+	for condition, expected := range map[string]int{
+		`"v1"`:                          http.StatusPartialContent,
+		`"v2"`:                          http.StatusOK,
+		`W/"v1"`:                        http.StatusOK, // If-Range requires strong comparison
+		"Mon, 06 May 2024 13:08:09 GMT": http.StatusPartialContent,
+		"Mon, 06 May 2024 13:08:10 GMT": http.StatusOK,
+		"garbage":                       http.StatusOK,
+	} {
+		request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.With(
+			Request.Header("Range", "bytes=0-1"),
+			Request.Header("If-Range", condition),
+		))
+		recorder := httptest.NewRecorder()
+
+		Flush(recorder, Response.Request(request), Response.ETag("v1", false), Response.LastModified(modified),
+			Response.BodyFromReader(strings.NewReader(rangeContent)))
+
+		should.So(t, recorder.Code, should.Equal, expected)
+	}
+}
+func TestResponseBodyFromReader_RangeCopyError(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/", Request.Header("Range", "bytes=0-1,5-6"))
+	writeErr := errors.New("write error")
+
+	err := FlushErr(&failingResponseWriter{ResponseRecorder: httptest.NewRecorder(), err: writeErr},
+		Response.Request(request), Response.BodyFromReader(strings.NewReader(rangeContent)))
+
+	should.So(t, errors.Is(err, ErrResponseCopy), should.BeTrue)
+	should.So(t, errors.Is(err, writeErr), should.BeTrue)
+}
+
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+	err error
+}
+
+func (this *failingResponseWriter) Write([]byte) (int, error) { return 0, this.err }
@@ -0,0 +1,149 @@
+package scuter
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Static evaluates the options once, rendering the status code, headers, and body (including any encoding) up front,
+// and returns an option which replays them on every Flush without encoding (or allocating, once the header of the
+// ResponseWriter has the rendered keys). It is intended for constant responses (ie. health checks, or fixed 404/405
+// errors) and is best called during initialization:
+//
+//	var unsupportedMediaType = scuter.Response.Static(
+//		scuter.Response.JSONErrors(http.StatusUnsupportedMediaType, scuter.ErrUnsupportedRequestContentType),
+//	)
+//
+// The response is rendered by the DefaultFlusher, and again by any other Flusher (see NewFlusher) the first time it
+// flushes the option, so that the JSON and error settings of each Flusher are honored.
+// Since later changes to the inputs wouldn't be reflected in the rendered response, Static panics if the options
+// depend on a request (Response.Request, Response.Negotiate, Response.OnError), a reader (Response.BodyFromReader,
+// Response.BodyWithAttachment), or a body value that refers to mutable data (a pointer, map, slice, etc.), as well
+// as when the body cannot be encoded. The bytes supplied to Response.BytesBody are copied and so are permitted.
+func (responseSingleton) Static(options ...ResponseOption) ResponseOption {
+	static := &staticResponse{options: slices.Clone(options)}
+	rendering, err := static.render(DefaultFlusher)
+	if err != nil {
+		panic(fmt.Sprintf("scuter: Static could not render the response: %s", err))
+	}
+	static.renderings.Store(DefaultFlusher, rendering)
+	return static.replay
+}
+
+// dynamicInput describes the first of the applied options whose input could change after being rendered by Static.
+func (this *responseConfig) dynamicInput() string {
+	switch {
+	case this.request != nil:
+		return "options bound to a request (Response.Request, Response.Negotiate)"
+	case len(this.errorHandlers) > 0:
+		return "error handlers (Response.OnError)"
+	case this.stream != nil:
+		return "streamed bodies (ie. Response.EventStream)"
+	case this.dataReader != nil:
+		return "bodies read from an io.Reader (Response.BodyFromReader, Response.BodyWithAttachment)"
+	case this.dataValue != nil && isMutableKind(reflect.TypeOf(this.dataValue).Kind()):
+		return fmt.Sprintf("body values which refer to mutable data (%T)", this.dataValue)
+	}
+	return ""
+}
+func isMutableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// staticResponse holds the options supplied to Static, along with their rendering by each Flusher.
+type staticResponse struct {
+	options    []ResponseOption
+	renderings sync.Map // *Flusher -> *staticRendering
+}
+
+// render applies the options to a *responseConfig of the Flusher and writes the response to a staticRendering,
+// panicking if the options depend on dynamic inputs.
+func (this *staticResponse) render(flusher *Flusher) (*staticRendering, error) {
+	config := flusher.configs.Get()
+	defer flusher.configs.Put(config)
+	rendering := &staticRendering{header: make(http.Header), status: http.StatusOK}
+	config.reset(flusher, rendering.Header())
+	for _, option := range this.options {
+		if option != nil {
+			option(config)
+		}
+	}
+	if reason := config.dynamicInput(); reason != "" {
+		panic(fmt.Sprintf("scuter: Static does not support %s", reason))
+	}
+	err := config.writeBody(rendering)
+	rendering.finish()
+	return rendering, err
+}
+
+// replay establishes the status code, headers, and body rendered by the Flusher, replacing any body supplied by
+// previous options. The rendering of a Flusher other than the DefaultFlusher is made (and kept) upon first use, in
+// which case a failure to encode results in the response rendered for it (ie. ErrInternalServerError).
+func (this *staticResponse) replay(config *responseConfig) {
+	rendered, ok := this.renderings.Load(config.flusher)
+	if !ok {
+		rendering, _ := this.render(config.flusher)
+		rendered, _ = this.renderings.LoadOrStore(config.flusher, rendering)
+	}
+	rendered.(*staticRendering).replay(config)
+}
+
+// staticRendering is the http.ResponseWriter with which Static renders a response, which it then replays.
+type staticRendering struct {
+	header  http.Header // only used while rendering
+	status  int
+	headers []staticHeader
+	body    bytes.Buffer
+}
+type staticHeader struct {
+	key     string
+	values  []string
+	replace bool // see staticReplacedHeaders
+}
+
+func (this *staticRendering) Header() http.Header         { return this.header }
+func (this *staticRendering) WriteHeader(status int)      { this.status = status }
+func (this *staticRendering) Write(p []byte) (int, error) { return this.body.Write(p) }
+func (this *staticRendering) finish() {
+	for key, values := range this.header {
+		replace := slices.Contains(staticReplacedHeaders, key)
+		this.headers = append(this.headers, staticHeader{key: key, values: slices.Clone(values), replace: replace})
+	}
+	slices.SortFunc(this.headers, func(a, b staticHeader) int { return strings.Compare(a.key, b.key) })
+	this.header = nil
+}
+
+// replay establishes the rendered status code, headers, and body, replacing any body supplied by previous options.
+// As with the options that were rendered, the header values are added to any set by previous options (or the
+// defaults of the Flusher), except for those of the staticReplacedHeaders, which replace them. The values are copied
+// (unless the header already has them) so that modifying them in one response can't affect another.
+func (this *staticRendering) replay(config *responseConfig) {
+	config.status = this.status
+	for _, header := range this.headers {
+		existing := config.header[header.key]
+		switch {
+		case !header.replace:
+			config.header[header.key] = append(existing, header.values...)
+		case !slices.Equal(existing, header.values):
+			config.header[header.key] = slices.Clone(header.values)
+		}
+	}
+	config.dataReader = nil
+	config.dataValue = nil
+	config.problem = false
+	config.jsonErrors.Errors = config.jsonErrors.Errors[:0]
+	config.data.Reset()
+	_, _ = config.data.Write(this.body.Bytes())
+}
+
+// staticReplacedHeaders are those which Flush (or the options which might be rendered) set rather than add to.
+var staticReplacedHeaders = []string{headerContentType, headerContentLength, headerETag, headerLastModified}
@@ -0,0 +1,134 @@
+package scuter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+func TestResponseStatic(t *testing.T) {
+	options := Response.With(
+		Response.Header("X-Custom", "value"),
+		Response.JSONErrors(http.StatusUnsupportedMediaType, ErrUnsupportedRequestContentType),
+	)
+	static := Response.Static(options)
+
+	for range 2 {
+		expected, actual := httptest.NewRecorder(), httptest.NewRecorder()
+		Flush(expected, options)
+		Flush(actual, static)
+
+		should.So(t, actual.Code, should.Equal, http.StatusUnsupportedMediaType)
+		should.So(t, actual.Code, should.Equal, expected.Code)
+		should.So(t, actual.Header(), should.Equal, expected.Header())
+		should.So(t, actual.Body.String(), should.Equal, expected.Body.String())
+	}
+}
+func TestResponseStatic_BytesBody(t *testing.T) {
+	body := []byte("OK")
+	static := Response.Static(Response.ContentType("text/plain"), Response.BytesBody(body))
+	body[0] = 'N'
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, static)
+
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Body.String(), should.Equal, "OK")
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "text/plain")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "2")
+}
+func TestResponseStatic_SubsequentOptions(t *testing.T) {
+	static := Response.Static(Response.StatusCode(http.StatusCreated), Response.Header("X-Custom", "a"))
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.JSONBody("replaced"), static, Response.Header("X-Custom", "b"))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusCreated)
+	should.So(t, recorder.Body.String(), should.Equal, "")
+	should.So(t, recorder.Header().Values("X-Custom"), should.Equal, []string{"a", "b"})
+}
+func TestResponseStatic_PreviousHeaders(t *testing.T) {
+	flusher := NewFlusher(Flushing.Header("X-Default", "1"))
+	static := Response.Static(
+		Response.Header("Vary", "Origin"),
+		Response.Header("X-Default", "2"),
+		Response.JSONBody("x"),
+	)
+	expected, actual := httptest.NewRecorder(), httptest.NewRecorder()
+
+	flusher.Flush(expected, Response.Header("Vary", "Accept-Language"), Response.ContentType("text/plain"),
+		Response.Header("Vary", "Origin"), Response.Header("X-Default", "2"), Response.JSONBody("x"))
+	flusher.Flush(actual, Response.Header("Vary", "Accept-Language"), Response.ContentType("text/plain"), static)
+
+	should.So(t, actual.Header().Values("Vary"), should.Equal, []string{"Accept-Language", "Origin"})
+	should.So(t, actual.Header().Values("X-Default"), should.Equal, []string{"1", "2"})
+	should.So(t, actual.Header(), should.Equal, expected.Header())
+	should.So(t, actual.Body.String(), should.Equal, expected.Body.String())
+}
+func TestResponseStatic_HeadersNotShared(t *testing.T) {
+	static := Response.Static(Response.Header("X-Custom", "value"), Response.StatusCode(http.StatusNoContent))
+	first, second := httptest.NewRecorder(), httptest.NewRecorder()
+
+	Flush(first, static)
+	first.Header()["X-Custom"][0] = "modified"
+	Flush(second, static)
+
+	should.So(t, second.Header().Get("X-Custom"), should.Equal, "value")
+}
+func TestResponseStatic_Flusher(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONIndent("", "  "), Flushing.ErrorFormat(ProblemFormat))
+	static := Response.Static(Response.JSONErrors(http.StatusNotFound, ErrInternalServerError))
+
+	for range 2 {
+		expected, actual := httptest.NewRecorder(), httptest.NewRecorder()
+		flusher.Flush(expected, Response.JSONErrors(http.StatusNotFound, ErrInternalServerError))
+		flusher.Flush(actual, static)
+
+		should.So(t, actual.Code, should.Equal, http.StatusNotFound)
+		should.So(t, actual.Header(), should.Equal, expected.Header())
+		should.So(t, actual.Body.String(), should.Equal, expected.Body.String())
+		should.So(t, strings.Contains(actual.Body.String(), "\n  \""), should.BeTrue)
+	}
+
+	recorder := httptest.NewRecorder()
+	Flush(recorder, static)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json; charset=utf-8")
+	should.So(t, recorder.Body.String(), should.Equal,
+		`{"errors":[{"name":"internal-server-error","message":"Internal Server Error"}]}`+"\n")
+}
+func TestResponseStatic_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are skewed by the race detector")
+	}
+	writer := &discardResponseWriter{header: make(http.Header)}
+	static := Response.Static(Response.JSONErrors(http.StatusMethodNotAllowed, ErrInternalServerError))
+
+	allocations := testing.AllocsPerRun(100, func() { Flush(writer, static) })
+
+	should.So(t, allocations, should.Equal, 0.0)
+}
+func TestResponseStatic_DynamicInputsPanic(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	cases := map[string]ResponseOption{
+		"request":     Response.Request(request),
+		"negotiate":   Response.Negotiated(request, "value"),
+		"on-error":    Response.OnError(func(context.Context, error) {}),
+		"stream":      Response.EventStream(request, func(*EventWriter) error { return nil }),
+		"reader":      Response.BodyFromReader(strings.NewReader("body")),
+		"attachment":  Response.BodyWithAttachment("file.txt", strings.NewReader("body")),
+		"pointer":     Response.JSONBody(&struct{}{}),
+		"map":         Response.JSONBody(map[string]string{}),
+		"slice":       Response.Body([]int{1}),
+		"unencodable": Response.JSONBody(failingMarshaler{}),
+	}
+	for name, option := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer func() { should.So(t, recover(), should.NOT.BeNil) }()
+			Response.Static(option)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package scuter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// NDJSONBody streams the values to the ResponseWriter as newline-delimited JSON (ie. application/x-ndjson), one
+// value per line, without first collecting them in memory (see NDJSONBodyErr).
+func NDJSONBody[T any](request *http.Request, values iter.Seq[T]) ResponseOption {
+	return NDJSONBodyErr(request, withoutErrors(values))
+}
+
+// NDJSONBodyErr streams the values to the ResponseWriter as newline-delimited JSON (ie. application/x-ndjson), one
+// value per line, without first collecting them in memory. What has been written is flushed to the client
+// periodically. Streaming stops early, without error, once the request's context is done (which happens when the
+// client disconnects). As the status code has already been written, an error yielded by the values (or encountered
+// while encoding one) ends the stream with a final line holding the Errors envelope (ie. `{"errors":[...]}`) with
+// the yielded Error, if it is one, or else ErrInternalServerError. The error is also passed to any ErrorHandler
+// (see Response.OnError) and returned by FlushErr, wrapped with ErrResponseStream.
+func NDJSONBodyErr[T any](request *http.Request, values iter.Seq2[T, error]) ResponseOption {
+	return streamValues(&valueStream[T]{request: request, values: values})
+}
+
+// JSONArrayBody streams the values to the ResponseWriter as a single JSON array, element by element, without first
+// collecting them in memory (see JSONArrayBodyErr).
+func JSONArrayBody[T any](request *http.Request, values iter.Seq[T]) ResponseOption {
+	return JSONArrayBodyErr(request, withoutErrors(values))
+}
+
+// JSONArrayBodyErr streams the values to the ResponseWriter as a single JSON array, element by element, without
+// first collecting them in memory. What has been written is flushed to the client periodically. Streaming stops
+// early once the request's context is done (which happens when the client disconnects). As the status code has
+// already been written, an error yielded by the values (or encountered while encoding one) ends the stream leaving
+// the array unterminated, so that the client fails to parse it rather than mistaking it for the complete array.
+// The error is also passed to any ErrorHandler (see Response.OnError) and returned by FlushErr, wrapped with
+// ErrResponseStream.
+func JSONArrayBodyErr[T any](request *http.Request, values iter.Seq2[T, error]) ResponseOption {
+	return streamValues(&valueStream[T]{request: request, values: values, array: true})
+}
+
+func streamValues[T any](stream *valueStream[T]) ResponseOption {
+	return func(config *responseConfig) {
+		if stream.request != nil {
+			config.request = stream.request
+		}
+		config.stream = stream
+	}
+}
+func withoutErrors[T any](values iter.Seq[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for value := range values {
+			if !yield(value, nil) {
+				return
+			}
+		}
+	}
+}
+
+type valueStream[T any] struct {
+	request *http.Request
+	values  iter.Seq2[T, error]
+	array   bool
+}
+
+func (this *valueStream[T]) prepare(config *responseConfig) {
+	if this.array {
+		config.header.Set(headerContentType, config.flusher.jsonContentType())
+	} else {
+		config.header.Set(headerContentType, ndjsonContentType)
+	}
+}
+func (this *valueStream[T]) write(config *responseConfig, response http.ResponseWriter) error {
+	writer := newStreamWriter(config, response)
+	defer writer.release()
+	codec := config.flusher.jsonCodec()
+	if this.array {
+		writer.buffer().WriteByte('[')
+	}
+	count := 0
+	for value, err := range this.values {
+		if this.context().Err() != nil {
+			return nil // the client went away, so there's no one left to tell
+		}
+		if err == nil && count > 0 && this.array {
+			writer.buffer().WriteByte(',')
+		}
+		if err == nil {
+			err = this.encode(writer, codec, value)
+		}
+		if err != nil {
+			return this.fail(writer, codec, err)
+		}
+		count++
+		if err = writer.flushPeriodically(); err != nil {
+			return err
+		}
+	}
+	if this.array {
+		writer.buffer().WriteString("]\n")
+	}
+	return writer.close()
+}
+func (this *valueStream[T]) encode(writer *streamWriter, codec Codec, value T) error {
+	length := writer.buffer().Len()
+	if err := codec.Encode(writer.buffer(), value); err != nil {
+		writer.buffer().Truncate(length)
+		return fmt.Errorf("%w: %w", ErrResponseEncode, err)
+	}
+	if data := writer.buffer().Bytes(); this.array && len(data) > length && data[len(data)-1] == '\n' {
+		writer.buffer().Truncate(len(data) - 1) // keeps the array on a single line
+	} else if !this.array {
+		compactRecord(writer.buffer(), length)
+	}
+	return nil
+}
+func (this *valueStream[T]) fail(writer *streamWriter, codec Codec, err error) error {
+	if !this.array {
+		var failure Error
+		if !errors.As(err, &failure) {
+			failure = ErrInternalServerError
+		}
+		length := writer.buffer().Len()
+		if codec.Encode(writer.buffer(), NewErrors(failure)) == nil {
+			compactRecord(writer.buffer(), length)
+		}
+	}
+	return errors.Join(err, writer.close())
+}
+
+// compactRecord ensures the JSON value encoded into the buffer beyond length occupies a single line (followed by a
+// newline), as NDJSON requires, even when the codec indented it (see Flushing.JSONIndent and Flushing.JSONMarshal).
+func compactRecord(buffer *bytes.Buffer, length int) {
+	record := bytes.TrimRight(buffer.Bytes()[length:], "\n")
+	if bytes.IndexByte(record, '\n') < 0 {
+		return // JSON strings can't contain raw newlines, so the value is already on a single line
+	}
+	record = bytes.Clone(record)
+	buffer.Truncate(length)
+	if err := json.Compact(buffer, record); err != nil {
+		buffer.Truncate(length)
+		buffer.Write(record) // not JSON after all, so there's no telling which newlines are significant
+	}
+	buffer.WriteByte('\n')
+}
+func (this *valueStream[T]) context() context.Context {
+	if this.request == nil {
+		return context.Background()
+	}
+	return this.request.Context()
+}
+
+// streamWriter buffers what is written by a responseStream (in the data buffer of the *responseConfig), writing and
+// flushing it to the client whenever enough has accumulated, or enough time has passed. When the stream is to be
+// compressed (see Flushing.Compression), what is written passes through a pooled compressor, which is flushed along
+// with the ResponseWriter, and closed (see close) once the stream is complete.
+type streamWriter struct {
+	config     *responseConfig
+	response   http.ResponseWriter
+	controller *http.ResponseController
+	flushed    time.Time
+	compressor compressor
+}
+
+func newStreamWriter(config *responseConfig, response http.ResponseWriter) *streamWriter {
+	config.data.Reset()
+	this := &streamWriter{
+		config:     config,
+		response:   response,
+		controller: http.NewResponseController(response),
+		flushed:    time.Now(),
+	}
+	if config.streamEncoding != "" {
+		this.compressor = compressors[config.streamEncoding].Get()
+		this.compressor.Reset(bodyWriter{config: config, response: response})
+	}
+	return this
+}
+
+func (this *streamWriter) buffer() *bytes.Buffer { return &this.config.data }
+func (this *streamWriter) flushPeriodically() error {
+	if this.buffer().Len() < streamFlushBytes && time.Since(this.flushed) < streamFlushInterval {
+		return nil
+	}
+	return this.flush()
+}
+func (this *streamWriter) flush() error {
+	if err := this.writeBuffer(); err != nil {
+		return err
+	}
+	if this.compressor != nil {
+		if err := this.compressor.Flush(); err != nil {
+			return fmt.Errorf("%w: %w", ErrResponseCopy, err)
+		}
+	}
+	this.flushed = time.Now()
+	if err := this.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (this *streamWriter) writeBuffer() (err error) {
+	if this.buffer().Len() == 0 {
+		return nil
+	}
+	if this.compressor == nil {
+		err = this.config.write(this.response, this.buffer().Bytes())
+	} else if _, err = this.compressor.Write(this.buffer().Bytes()); err != nil {
+		err = fmt.Errorf("%w: %w", ErrResponseCopy, err)
+	}
+	this.buffer().Reset()
+	return err
+}
+
+// close writes what remains of the stream, including the end of any compressed data, and flushes it to the client.
+func (this *streamWriter) close() error {
+	if this.compressor == nil {
+		return this.flush()
+	}
+	err := this.writeBuffer()
+	if closeErr := this.compressor.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("%w: %w", ErrResponseCopy, closeErr)
+	}
+	this.release()
+	return errors.Join(err, this.flush())
+}
+
+// release returns any compressor to its pool (see close), once the stream is complete or abandoned.
+func (this *streamWriter) release() {
+	if this.compressor != nil {
+		releaseCompressor(compressors[this.config.streamEncoding], this.compressor)
+		this.compressor = nil
+	}
+}
+
+const (
+	ndjsonContentType   = "application/x-ndjson"
+	streamFlushBytes    = 32 << 10
+	streamFlushInterval = time.Second
+)
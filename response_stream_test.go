@@ -0,0 +1,148 @@
+package scuter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+type streamModel struct {
+	ID int `json:"id"`
+}
+
+func streamModels(count int, failure error) iter.Seq2[streamModel, error] {
+	return func(yield func(streamModel, error) bool) {
+		for x := range count {
+			if !yield(streamModel{ID: x + 1}, nil) {
+				return
+			}
+		}
+		if failure != nil {
+			yield(streamModel{}, failure)
+		}
+	}
+}
+
+func TestNDJSONBody(t *testing.T) {
+	request := NewTestRequest(context.Background(), http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+
+	err := FlushErr(recorder, NDJSONBody(request, slices.Values([]streamModel{{ID: 1}, {ID: 2}})))
+
+	should.So(t, err, should.BeNil)
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/x-ndjson")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "")
+	should.So(t, recorder.Body.String(), should.Equal, "{\"id\":1}\n{\"id\":2}\n")
+	should.So(t, recorder.Flushed, should.BeTrue)
+}
+func TestNDJSONBody_IgnoresIndentation(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONIndent("", "  "))
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, NDJSONBodyErr(nil, streamModels(2, nil)))
+
+	should.So(t, recorder.Body.String(), should.Equal, "{\"id\":1}\n{\"id\":2}\n")
+}
+func TestNDJSONBody_CompactsMarshaledValues(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONMarshal(func(v any) ([]byte, error) { return json.MarshalIndent(v, "", "\t") }))
+	recorder := httptest.NewRecorder()
+
+	flusher.Flush(recorder, NDJSONBodyErr(nil, streamModels(2, Error{Name: "upstream-failure"})))
+
+	should.So(t, recorder.Body.String(), should.Equal,
+		"{\"id\":1}\n{\"id\":2}\n{\"errors\":[{\"name\":\"upstream-failure\"}]}\n")
+}
+func TestNDJSONBodyErr_Error(t *testing.T) {
+	failure := Error{Name: "upstream-failure", Message: "The upstream failed."}
+	recorder := httptest.NewRecorder()
+
+	err := FlushErr(recorder, NDJSONBodyErr(nil, streamModels(1, fmt.Errorf("wrapped: %w", failure))))
+
+	should.So(t, errors.Is(err, ErrResponseStream), should.BeTrue)
+	should.So(t, errors.Is(err, failure), should.BeTrue)
+	should.So(t, recorder.Code, should.Equal, http.StatusOK)
+	should.So(t, recorder.Body.String(), should.Equal, ""+
+		"{\"id\":1}\n"+
+		"{\"errors\":[{\"name\":\"upstream-failure\",\"message\":\"The upstream failed.\"}]}\n")
+}
+func TestNDJSONBodyErr_EncodeError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	values := func(yield func(any, error) bool) { _ = yield(1, nil) && yield(make(chan int), nil) }
+
+	err := FlushErr(recorder, NDJSONBodyErr(nil, iter.Seq2[any, error](values)))
+
+	should.So(t, errors.Is(err, ErrResponseEncode), should.BeTrue)
+	should.So(t, recorder.Body.String(), should.Equal, ""+
+		"1\n"+
+		"{\"errors\":[{\"name\":\"internal-server-error\",\"message\":\"Internal Server Error\"}]}\n")
+}
+func TestJSONArrayBody(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, JSONArrayBody(nil, slices.Values([]streamModel{{ID: 1}, {ID: 2}, {ID: 3}})))
+
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, jsonContentType)
+	should.So(t, recorder.Body.String(), should.Equal, "[{\"id\":1},{\"id\":2},{\"id\":3}]\n")
+}
+func TestJSONArrayBody_Empty(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, JSONArrayBody(nil, slices.Values([]int(nil))))
+
+	should.So(t, recorder.Body.String(), should.Equal, "[]\n")
+}
+func TestJSONArrayBodyErr_Error(t *testing.T) {
+	failure := errors.New("failure")
+	recorder := httptest.NewRecorder()
+
+	err := FlushErr(recorder, JSONArrayBodyErr(nil, streamModels(2, failure)))
+
+	should.So(t, errors.Is(err, failure), should.BeTrue)
+	should.So(t, recorder.Body.String(), should.Equal, "[{\"id\":1},{\"id\":2}")
+}
+func TestJSONArrayBody_ClientDisconnected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	request := NewTestRequest(ctx, http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+	yielded := 0
+	values := func(yield func(int) bool) {
+		for yielded = 1; yield(yielded); yielded++ {
+			if yielded == 2 {
+				cancel()
+			}
+		}
+	}
+
+	err := FlushErr(recorder, JSONArrayBody(request, values))
+
+	should.So(t, err, should.BeNil)
+	should.So(t, yielded, should.Equal, 3)
+	should.So(t, recorder.Body.String(), should.Equal, "")
+}
+
+type flushCountingWriter struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (this *flushCountingWriter) Flush() { this.flushes++ }
+
+func TestJSONArrayBody_FlushesPeriodically(t *testing.T) {
+	writer := &flushCountingWriter{ResponseRecorder: httptest.NewRecorder()}
+	element := strings.Repeat("x", 1024)
+
+	Flush(writer, JSONArrayBody(nil, slices.Values(slices.Repeat([]string{element}, 100))))
+
+	should.So(t, writer.flushes, should.Equal, 4)
+	should.So(t, writer.Body.Len(), should.Equal, len("[]\n")+100*len(`"`+element+`"`)+99)
+}
@@ -1,9 +1,12 @@
 package scuter
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -78,6 +81,19 @@ func TestResponseWith(t *testing.T) {
 	should.So(t, recorder.Code, should.Equal, http.StatusTeapot)
 	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, "application/json; charset=utf-8")
 }
+func TestResponseWith_Composed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var result ResponseOption
+	should.So(t, Response.With(result, nil), should.BeNil)
+	for x := range 6 {
+		result = Response.With(result, Response.Header("X-Sequence", strconv.Itoa(x)))
+	}
+
+	Flush(recorder, Response.With(result, nil, Response.StatusCode(http.StatusTeapot)))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusTeapot)
+	should.So(t, recorder.Header().Values("X-Sequence"), should.Equal, []string{"0", "1", "2", "3", "4", "5"})
+}
 func TestResponseIf_False(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	Flush(recorder, Response.If(false, Response.StatusCode(http.StatusTeapot)))
@@ -126,3 +142,184 @@ func (this *Closer) Close() error {
 	this.closed++
 	return this.closeErr
 }
+
+func TestFlushErr_EncodeError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	err := FlushErr(recorder, Response.JSONBody(make(chan int)))
+
+	should.So(t, errors.Is(err, ErrResponseEncode), should.BeTrue)
+}
+func TestFlush_EncodeErrorBecomesInternalServerError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder,
+		Response.StatusCode(http.StatusCreated),
+		Response.Header("X-Custom", "value"),
+		Response.JSONBody(failingMarshaler{}),
+	)
+
+	const expectedBody = `{"errors":[{"name":"internal-server-error","message":"Internal Server Error"}]}` + "\n"
+	should.So(t, recorder.Code, should.Equal, http.StatusInternalServerError)
+	should.So(t, recorder.Body.String(), should.Equal, expectedBody)
+	should.So(t, recorder.Header().Get("Content-Type"), should.Equal, jsonContentType)
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, strconv.Itoa(len(expectedBody)))
+	should.So(t, recorder.Header().Get("X-Custom"), should.Equal, "value")
+}
+func TestFlush_ContentLength(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	Flush(recorder, Response.StatusCode(http.StatusCreated), Response.JSONBody([]int{1, 2, 3}))
+
+	should.So(t, recorder.Code, should.Equal, http.StatusCreated)
+	should.So(t, recorder.Body.String(), should.Equal, "[1,2,3]\n")
+	should.So(t, recorder.Header().Get("Content-Length"), should.Equal, "8")
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("marshal failure") }
+
+func TestFlushErr_CopyAndCloseErrors(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	readErr, closeErr := errors.New("read error"), errors.New("close error")
+	readCloser := &Closer{
+		Reader:   &Reader{Reader: strings.NewReader("Hello"), readErr: readErr},
+		closeErr: closeErr,
+	}
+
+	err := FlushErr(recorder, Response.BodyFromReader(readCloser))
+
+	should.So(t, errors.Is(err, ErrResponseCopy), should.BeTrue)
+	should.So(t, errors.Is(err, readErr), should.BeTrue)
+	should.So(t, errors.Is(err, ErrResponseClose), should.BeTrue)
+	should.So(t, errors.Is(err, closeErr), should.BeTrue)
+	should.So(t, readCloser.closed, should.Equal, 1)
+}
+func TestFlushErr_NoError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	handled := 0
+
+	err := FlushErr(recorder,
+		Response.JSONBody([]int{1}),
+		Response.OnError(func(context.Context, error) { handled++ }),
+	)
+
+	should.So(t, err, should.BeNil)
+	should.So(t, handled, should.Equal, 0)
+}
+func TestResponseOnError(t *testing.T) {
+	type contextKey struct{}
+	ctx := context.WithValue(t.Context(), contextKey{}, "value")
+	request := NewTestRequest(ctx, http.MethodGet, "/")
+	recorder := httptest.NewRecorder()
+	var handled []error
+	var values []any
+	handler := func(ctx context.Context, err error) {
+		handled = append(handled, err)
+		values = append(values, ctx.Value(contextKey{}))
+	}
+
+	Flush(recorder,
+		Response.Request(request),
+		Response.OnError(handler),
+		Response.OnError(nil),
+		Response.OnError(handler),
+		Response.JSONBody(make(chan int)),
+	)
+
+	should.So(t, len(handled), should.Equal, 2)
+	should.So(t, errors.Is(handled[0], ErrResponseEncode), should.BeTrue)
+	should.So(t, values, should.Equal, []any{"value", "value"})
+}
+func TestResponseOnError_WithoutRequest(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var handled context.Context
+
+	Flush(recorder,
+		Response.OnError(func(ctx context.Context, _ error) { handled = ctx }),
+		Response.JSONBody(make(chan int)),
+	)
+
+	should.So(t, handled, should.Equal, context.Background())
+}
+
+type discardResponseWriter struct{ header http.Header }
+
+func (this *discardResponseWriter) Header() http.Header         { return this.header }
+func (this *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (this *discardResponseWriter) WriteHeader(int)             {}
+
+type allocationModel struct {
+	ID      uint64 `json:"id"`
+	Details string `json:"details"`
+}
+
+// shellResponse mimics a typical shell, which builds its response in a helper func whose result escapes.
+//
+//go:noinline
+func shellResponse(code int, model *allocationModel) ResponseOption {
+	if model == nil {
+		return Response.JSONErrors(code, ErrInternalServerError)
+	}
+	return Response.With(Response.StatusCode(code), Response.JSONBody(model))
+}
+
+// raceEnabled reports whether the race detector, which allocates on its own account, is enabled (see race_test.go).
+var raceEnabled bool
+
+// TestFlush_Allocations establishes that options supplied directly to Flush don't allocate, and that those which
+// escape (ie. returned from a helper func) cost no more than one allocation for each of their closures.
+func TestFlush_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are skewed by the race detector")
+	}
+	writer := &discardResponseWriter{header: make(http.Header)}
+	model := &allocationModel{ID: 42, Details: "a task whose serialized form is more than a hundred bytes long, give or take"}
+	cases := map[string]struct {
+		allocations float64
+		flush       func()
+	}{
+		"status+json-body": {0, func() {
+			Flush(writer, Response.StatusCode(http.StatusCreated), Response.JSONBody(model))
+		}},
+		"status+errors": {0, func() {
+			Flush(writer, Response.JSONErrors(http.StatusBadRequest, ErrInternalServerError))
+		}},
+		"status+error": {0, func() {
+			Flush(writer, Response.StatusCode(http.StatusUnprocessableEntity), Response.JSONError(ErrInternalServerError))
+		}},
+		"shell-json-body": {2, func() { // JSONBody and With (StatusCode is preallocated)
+			Flush(writer, shellResponse(http.StatusCreated, model))
+		}},
+		"shell-errors": {1, func() { // JSONErrors
+			Flush(writer, shellResponse(http.StatusInternalServerError, nil))
+		}},
+		"shell-composed": {4, func() { // two each of JSONError and With (composing with nil is free)
+			result := Response.With(nil, Response.JSONError(ErrInternalServerError))
+			result = Response.With(result, Response.JSONError(ErrInternalServerError))
+			Flush(writer, Response.With(result, Response.StatusCode(http.StatusUnprocessableEntity)))
+		}},
+	}
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			should.So(t, testing.AllocsPerRun(100, test.flush), should.Equal, test.allocations)
+		})
+	}
+}
+
+func BenchmarkFlush_StatusAndJSONBody(b *testing.B) {
+	writer := &discardResponseWriter{header: make(http.Header)}
+	model := &allocationModel{ID: 42, Details: "details"}
+	b.ReportAllocs()
+	for b.Loop() {
+		Flush(writer, shellResponse(http.StatusCreated, model))
+	}
+}
+func BenchmarkFlush_StatusAndErrors(b *testing.B) {
+	writer := &discardResponseWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	for b.Loop() {
+		Flush(writer, shellResponse(http.StatusInternalServerError, nil))
+	}
+}
@@ -0,0 +1,50 @@
+package scuter
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Resetter is implemented by models which know how to return themselves to their initial state (see NewShell).
+type Resetter interface {
+	Reset()
+}
+
+// Shell is an http.Handler which serves each request with a model taken from a Pool, taking care of the lifecycle
+// of the model (which every handler following the pattern of a pooled model would otherwise repeat): the model is
+// reset before each use, and returned to the Pool only after the response has been flushed, including any body
+// streamed from (or referring to) the model, even when serving the request panics. A Shell is safe for concurrent use.
+type Shell[M any] struct {
+	flusher *Flusher
+	models  *Pool[M]
+	reset   func(M)
+	serve   func(*http.Request, M) ResponseOption
+}
+
+// NewShell returns a Shell which creates models with create, resets them with reset (or, when reset is nil, with
+// their Reset method, see Resetter), and serves each request with serve, whose response is flushed by the flusher
+// (or, when nil, the DefaultFlusher). NewShell panics when reset is nil and M doesn't implement Resetter.
+func NewShell[M any](
+	flusher *Flusher, create func() M, reset func(M), serve func(*http.Request, M) ResponseOption,
+) *Shell[M] {
+	model := create()
+	if reset == nil {
+		if _, ok := any(model).(Resetter); !ok {
+			panic(fmt.Sprintf("scuter: NewShell requires a reset func or a model implementing Resetter, not %T", model))
+		}
+		reset = func(model M) { any(model).(Resetter).Reset() }
+	}
+	if flusher == nil {
+		flusher = DefaultFlusher
+	}
+	this := &Shell[M]{flusher: flusher, models: NewPool(create), reset: reset, serve: serve}
+	this.models.Put(model)
+	return this
+}
+
+func (this *Shell[M]) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	model := this.models.Get()
+	defer this.models.Put(model)
+	this.reset(model)
+	this.flusher.Flush(response, this.serve(request, model))
+}
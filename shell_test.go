@@ -0,0 +1,97 @@
+package scuter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/smarty/scuter/internal/should"
+)
+
+type shellModel struct {
+	values    []int
+	streaming bool
+	resets    int
+}
+
+func (this *shellModel) Reset() {
+	this.values = this.values[:0]
+	this.resets++
+}
+
+func TestShell_ResetsBeforeEachUse(t *testing.T) {
+	shell := NewShell(nil, func() *shellModel { return &shellModel{} }, nil, func(request *http.Request, model *shellModel) ResponseOption {
+		should.So(t, len(model.values), should.Equal, 0)
+		should.So(t, model.resets > 0, should.BeTrue)
+		model.values = append(model.values, 1, 2, 3)
+		return Response.JSONBody(model.values)
+	})
+	for range 3 {
+		recorder := httptest.NewRecorder()
+
+		shell.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		should.So(t, recorder.Body.String(), should.Equal, "[1,2,3]\n")
+	}
+}
+func TestShell_ModelsReturnedOnlyOnceStreamed(t *testing.T) {
+	var mutex sync.Mutex
+	reset := func(model *shellModel) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		should.So(t, model.streaming, should.BeFalse)
+		model.values = model.values[:0]
+	}
+	shell := NewShell(nil, func() *shellModel { return &shellModel{} }, reset, func(request *http.Request, model *shellModel) ResponseOption {
+		model.values = append(model.values, 1, 2, 3)
+		model.streaming = true
+		return NDJSONBody(request, func(yield func(int) bool) {
+			defer func() { mutex.Lock(); model.streaming = false; mutex.Unlock() }()
+			for _, value := range model.values {
+				if !yield(value) {
+					return
+				}
+			}
+		})
+	})
+	var waiter sync.WaitGroup
+	for range 10 {
+		waiter.Go(func() {
+			recorder := httptest.NewRecorder()
+
+			shell.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+			should.So(t, recorder.Body.String(), should.Equal, "1\n2\n3\n")
+		})
+	}
+	waiter.Wait()
+}
+func TestShell_Panic(t *testing.T) {
+	shell := NewShell(nil, func() *shellModel { return &shellModel{} }, nil, func(*http.Request, *shellModel) ResponseOption {
+		panic("boink")
+	})
+	defer func() { should.So(t, recover(), should.Equal, "boink") }()
+
+	shell.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+func TestShell_ResetRequired(t *testing.T) {
+	defer func() {
+		should.So(t, recover(), should.Equal,
+			"scuter: NewShell requires a reset func or a model implementing Resetter, not []int")
+	}()
+
+	NewShell(nil, func() []int { return nil }, nil, func(*http.Request, []int) ResponseOption { return nil })
+}
+func TestShell_Flusher(t *testing.T) {
+	flusher := NewFlusher(Flushing.JSONIndent("", "  "))
+	shell := NewShell(flusher, func() *shellModel { return &shellModel{} }, nil, func(request *http.Request, model *shellModel) ResponseOption {
+		model.values = append(model.values, 1)
+		return Response.JSONBody(model.values)
+	})
+	recorder := httptest.NewRecorder()
+
+	shell.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+	should.So(t, recorder.Body.String(), should.Equal, "[\n  1\n]\n")
+}
@@ -0,0 +1,223 @@
+package scuter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownRequestField = Error{
+		Name:    "unknown-request-field",
+		Message: "The field is not recognized.",
+	}
+	ErrDuplicateRequestField = Error{
+		Name:    "duplicate-request-field",
+		Message: "The field appeared more than once.",
+	}
+	ErrTrailingRequestData = Error{
+		Fields:  []string{"body"},
+		Name:    "trailing-request-data",
+		Message: "The body contained data beyond the first JSON value.",
+	}
+)
+
+// strictJSONScanner walks the tokens of a JSON document alongside the type of the value it will be unmarshaled
+// into, recording unknown and duplicate object keys (with Fields identifying the offending JSON path) and any data
+// beyond the first JSON value. In locate mode (see locateJSONError) it instead unmarshals each value handled by a
+// json.Unmarshaler or encoding.TextUnmarshaler until one fails.
+type strictJSONScanner struct {
+	decoder *json.Decoder
+	errs    []Error
+
+	locate      bool
+	locatedPath string
+	locatedType reflect.Type
+}
+
+// scanStrictJSON returns the errors found in data according to the rules of strict mode, or the first error
+// encountered if data isn't syntactically valid.
+func scanStrictJSON(data []byte, v any) ([]Error, error) {
+	this := newStrictJSONScanner(data)
+	if err := this.scanValue(strictJSONType(reflect.TypeOf(v)), "body", 0); err != nil {
+		return nil, err
+	}
+	if _, err := this.decoder.Token(); err != io.EOF {
+		this.errs = append(this.errs, ErrTrailingRequestData)
+	}
+	return this.errs, nil
+}
+func newStrictJSONScanner(data []byte) *strictJSONScanner {
+	this := &strictJSONScanner{decoder: json.NewDecoder(bytes.NewReader(data))}
+	this.decoder.UseNumber()
+	return this
+}
+func (this *strictJSONScanner) scanValue(target reflect.Type, path string, depth int) error {
+	if depth > maxStrictJSONDepth {
+		return errStrictJSONTooDeep
+	}
+	if isJSONUnmarshaler(target) {
+		return this.scanUnmarshaler(target, path)
+	}
+	token, err := this.decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		return this.scanObject(target, path, depth)
+	case json.Delim('['):
+		return this.scanArray(target, path, depth)
+	}
+	return nil
+}
+func (this *strictJSONScanner) scanUnmarshaler(target reflect.Type, path string) error {
+	var raw json.RawMessage
+	if err := this.decoder.Decode(&raw); err != nil {
+		return err
+	}
+	if this.locate && json.Unmarshal(raw, reflect.New(target).Interface()) != nil {
+		this.locatedPath, this.locatedType = path, target
+		return errJSONLocated
+	}
+	return nil
+}
+func (this *strictJSONScanner) scanObject(target reflect.Type, path string, depth int) error {
+	seen := make(map[string]struct{})
+	for this.decoder.More() {
+		token, err := this.decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		field := path + "." + key
+		member, name, known := strictJSONMemberType(target, key)
+		if _, duplicate := seen[name]; duplicate {
+			this.record(ErrDuplicateRequestField, field)
+		}
+		seen[name] = struct{}{}
+		if !known {
+			this.record(ErrUnknownRequestField, field)
+		}
+		if err = this.scanValue(member, field, depth+1); err != nil {
+			return err
+		}
+	}
+	_, err := this.decoder.Token() // '}'
+	return err
+}
+func (this *strictJSONScanner) scanArray(target reflect.Type, path string, depth int) error {
+	var element reflect.Type
+	if target != nil && (target.Kind() == reflect.Slice || target.Kind() == reflect.Array) {
+		element = strictJSONType(target.Elem())
+	}
+	for x := 0; this.decoder.More(); x++ {
+		if err := this.scanValue(element, path+"["+strconv.Itoa(x)+"]", depth+1); err != nil {
+			return err
+		}
+	}
+	_, err := this.decoder.Token() // ']'
+	return err
+}
+func (this *strictJSONScanner) record(template Error, field string) {
+	this.errs = append(this.errs, fieldError(template, field))
+}
+
+// strictJSONType dereferences pointers and returns nil for interfaces, whose contents can't be checked.
+func strictJSONType(target reflect.Type) reflect.Type {
+	for target != nil && target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	if target == nil || target.Kind() == reflect.Interface {
+		return nil
+	}
+	return target
+}
+
+// isJSONUnmarshaler reports whether values of target unmarshal themselves, such that their contents can't be checked.
+func isJSONUnmarshaler(target reflect.Type) bool {
+	if target == nil {
+		return false
+	}
+	pointer := reflect.PointerTo(target)
+	return pointer.Implements(jsonUnmarshalerType) || pointer.Implements(textUnmarshalerType)
+}
+
+// strictJSONMemberType returns the type of the member named by key within target, the name of the member the value
+// will be stored in (which, for a struct, might differ from key in case), and whether such a member exists.
+func strictJSONMemberType(target reflect.Type, key string) (reflect.Type, string, bool) {
+	if target == nil {
+		return nil, key, true
+	}
+	switch target.Kind() {
+	case reflect.Map:
+		return strictJSONType(target.Elem()), key, true
+	case reflect.Struct:
+		fields := strictJSONFields(target)
+		if field, ok := fields[key]; ok {
+			return strictJSONType(field), key, true
+		}
+		for name, field := range fields {
+			if strings.EqualFold(name, key) { // encoding/json matches keys case-insensitively
+				return strictJSONType(field), name, true
+			}
+		}
+		return nil, key, false
+	}
+	return nil, key, true // a type mismatch, which will be reported by json.Unmarshal
+}
+
+// strictJSONFields returns the types of the fields of target keyed by the names encoding/json would use for them,
+// including those promoted from embedded structs.
+func strictJSONFields(target reflect.Type) map[string]reflect.Type {
+	if cached, ok := strictJSONFieldCache.Load(target); ok {
+		return cached.(map[string]reflect.Type)
+	}
+	fields := make(map[string]reflect.Type)
+	var promoted []reflect.Type
+	for x := 0; x < target.NumField(); x++ {
+		field := target.Field(x)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			if embedded := strictJSONType(field.Type); embedded != nil && embedded.Kind() == reflect.Struct {
+				promoted = append(promoted, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	for _, embedded := range promoted {
+		for name, field := range strictJSONFields(embedded) {
+			if _, shadowed := fields[name]; !shadowed {
+				fields[name] = field
+			}
+		}
+	}
+	strictJSONFieldCache.Store(target, fields)
+	return fields
+}
+
+var (
+	strictJSONFieldCache sync.Map
+	jsonUnmarshalerType  = reflect.TypeFor[json.Unmarshaler]()
+
+	errStrictJSONTooDeep = errors.New("json: exceeded max depth")
+)
+
+// maxStrictJSONDepth matches the nesting limit enforced by encoding/json.
+const maxStrictJSONDepth = 10000
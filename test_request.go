@@ -17,6 +17,7 @@ func NewTestRequest(ctx context.Context, method, target string, options ...Reque
 		body:    bytes.NewBuffer(nil),
 		headers: make(http.Header),
 		query:   make(url.Values),
+		path:    make(map[string]string),
 	}
 	Request.With(options...)(&config)
 
@@ -36,12 +37,17 @@ func NewTestRequest(ctx context.Context, method, target string, options ...Reque
 		}
 	}
 
+	for key, value := range config.path {
+		request.SetPathValue(key, value)
+	}
+
 	return request
 }
 
 type requestConfig struct {
 	query   url.Values
 	headers http.Header
+	path    map[string]string
 	body    *bytes.Buffer
 }
 
@@ -69,6 +75,11 @@ func (requestSingleton) Query(key, value string) RequestOption {
 	return func(c *requestConfig) { c.query.Add(key, value) }
 }
 
+// PathValue returns an option which will set the provided key/value as a path wildcard (see http.Request.PathValue).
+func (requestSingleton) PathValue(key, value string) RequestOption {
+	return func(c *requestConfig) { c.path[key] = value }
+}
+
 // Header returns an option which will add the provided key/value to the request header.
 func (requestSingleton) Header(key, value string) RequestOption {
 	return func(c *requestConfig) { c.headers.Add(key, value) }
@@ -71,3 +71,9 @@ func TestJSONBodyErrorPanic(t *testing.T) {
 		Request.JSONBody(make(chan int)),
 	)
 }
+func TestPathValue(t *testing.T) {
+	request := NewTestRequest(t.Context(), http.MethodGet, "/target/42",
+		Request.PathValue("id", "42"),
+	)
+	should.So(t, request.PathValue("id"), should.Equal, "42")
+}
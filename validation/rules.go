@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/smarty/scuter"
+)
+
+var (
+	ErrRequired = scuter.Error{
+		Name:    "value-required",
+		Message: "The value is required.",
+	}
+	ErrTooShort = scuter.Error{
+		Name:    "value-too-short",
+		Message: "The value is too short.",
+	}
+	ErrTooLong = scuter.Error{
+		Name:    "value-too-long",
+		Message: "The value is too long.",
+	}
+	ErrTooSmall = scuter.Error{
+		Name:    "value-too-small",
+		Message: "The value is too small.",
+	}
+	ErrTooLarge = scuter.Error{
+		Name:    "value-too-large",
+		Message: "The value is too large.",
+	}
+	ErrMalformed = scuter.Error{
+		Name:    "value-malformed",
+		Message: "The value is not in the expected format.",
+	}
+	ErrNotAllowed = scuter.Error{
+		Name:    "value-not-allowed",
+		Message: "The value is not one of those allowed.",
+	}
+	ErrTooEarly = scuter.Error{
+		Name:    "value-too-early",
+		Message: "The value is too early.",
+	}
+	ErrTooLate = scuter.Error{
+		Name:    "value-too-late",
+		Message: "The value is too late.",
+	}
+	ErrInvalid = scuter.Error{
+		Name:    "value-invalid",
+		Message: "The value is invalid.",
+	}
+)
+
+// Rule is a single condition which a value must satisfy (see Field), reported (when not satisfied) as a copy of the
+// corresponding error (ie. ErrTooLong), whose Name is stable and whose Message describes the condition.
+type Rule[T any] struct {
+	valid    func(T) bool
+	failure  scuter.Error
+	required bool
+}
+
+// Error returns a copy of the rule which is reported as the provided error instead (its Fields are replaced).
+func (this Rule[T]) Error(failure scuter.Error) Rule[T] {
+	this.failure = failure
+	return this
+}
+
+// Must returns a rule satisfied by values for which the predicate returns true, and otherwise reported as the
+// provided error (see ErrInvalid).
+func Must[T any](predicate func(T) bool, failure scuter.Error) Rule[T] {
+	return Rule[T]{valid: predicate, failure: failure}
+}
+
+// Required is satisfied by values which are present: not the zero value, nor a string containing only whitespace,
+// nor an empty slice or map.
+func Required[T any]() Rule[T] {
+	return Rule[T]{valid: func(value T) bool { return !isZero(value) }, failure: ErrRequired, required: true}
+}
+
+// MinLength is satisfied by strings of at least n characters.
+func MinLength(n int) Rule[string] {
+	return Rule[string]{
+		valid:   func(value string) bool { return utf8.RuneCountInString(value) >= n },
+		failure: describe(ErrTooShort, "The value must have at least %d characters.", n),
+	}
+}
+
+// MaxLength is satisfied by strings of at most n characters.
+func MaxLength(n int) Rule[string] {
+	return Rule[string]{
+		valid:   func(value string) bool { return utf8.RuneCountInString(value) <= n },
+		failure: describe(ErrTooLong, "The value must have at most %d characters.", n),
+	}
+}
+
+// MinItems is satisfied by slices of at least n elements.
+func MinItems[E any](n int) Rule[[]E] {
+	return Rule[[]E]{
+		valid:   func(value []E) bool { return len(value) >= n },
+		failure: describe(ErrTooShort, "The value must have at least %d items.", n),
+	}
+}
+
+// MaxItems is satisfied by slices of at most n elements.
+func MaxItems[E any](n int) Rule[[]E] {
+	return Rule[[]E]{
+		valid:   func(value []E) bool { return len(value) <= n },
+		failure: describe(ErrTooLong, "The value must have at most %d items.", n),
+	}
+}
+
+// Min is satisfied by values of at least n.
+func Min[T cmp.Ordered](n T) Rule[T] {
+	return Rule[T]{
+		valid:   func(value T) bool { return value >= n },
+		failure: describe(ErrTooSmall, "The value must be at least %v.", n),
+	}
+}
+
+// Max is satisfied by values of at most n.
+func Max[T cmp.Ordered](n T) Rule[T] {
+	return Rule[T]{
+		valid:   func(value T) bool { return value <= n },
+		failure: describe(ErrTooLarge, "The value must be at most %v.", n),
+	}
+}
+
+// Matches is satisfied by strings which match the pattern, which is described in the message (ie. "an email
+// address", resulting in "The value must be an email address.") unless the description is empty.
+func Matches(pattern *regexp.Regexp, description string) Rule[string] {
+	failure := ErrMalformed
+	if description != "" {
+		failure = describe(ErrMalformed, "The value must be %s.", description)
+	}
+	return Rule[string]{valid: pattern.MatchString, failure: failure}
+}
+
+// OneOf is satisfied by any of the allowed values.
+func OneOf[T comparable](allowed ...T) Rule[T] {
+	descriptions := make([]string, 0, len(allowed))
+	for _, value := range allowed {
+		descriptions = append(descriptions, fmt.Sprint(value))
+	}
+	return Rule[T]{
+		valid:   func(value T) bool { return slices.Contains(allowed, value) },
+		failure: describe(ErrNotAllowed, "The value must be one of: %s.", strings.Join(descriptions, ", ")),
+	}
+}
+
+// Before is satisfied by times before t.
+func Before(t time.Time) Rule[time.Time] {
+	return Rule[time.Time]{
+		valid:   func(value time.Time) bool { return value.Before(t) },
+		failure: describe(ErrTooLate, "The value must be before %s.", t.Format(time.RFC3339)),
+	}
+}
+
+// After is satisfied by times after t.
+func After(t time.Time) Rule[time.Time] {
+	return Rule[time.Time]{
+		valid:   func(value time.Time) bool { return value.After(t) },
+		failure: describe(ErrTooEarly, "The value must be after %s.", t.Format(time.RFC3339)),
+	}
+}
+
+func describe(template scuter.Error, format string, args ...any) scuter.Error {
+	template.Message = fmt.Sprintf(format, args...)
+	return template
+}
@@ -0,0 +1,136 @@
+// Package validation composes rules which validate the values of a request model, producing scuter.Error values
+// whose Fields identify the offending values (ie. "body.items[2].name") and whose Names are stable:
+//
+//	result, ok := validation.Validate(
+//		validation.Field("body.due_date", model.DueDate, validation.Required[time.Time](), validation.After(now)),
+//		validation.Field("body.details", model.Details, validation.Required[string](), validation.MaxLength(500)),
+//		validation.Each("body.tags", model.Tags, func(tag string) []validation.Check {
+//			return []validation.Check{validation.Field("", tag, validation.OneOf("home", "work"))}
+//		}),
+//	)
+//	if !ok {
+//		return result
+//	}
+package validation
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/smarty/scuter"
+)
+
+// Validate runs the checks, returning a JSON error response with 422 Unprocessable Entity containing every error
+// found, which can be sent to the client with scuter.Flush, or nil and true if there were none.
+func Validate(checks ...Check) (scuter.ResponseOption, bool) {
+	if errs := Errors(checks...); len(errs) > 0 {
+		return scuter.Response.JSONErrors(http.StatusUnprocessableEntity, errs...), false
+	}
+	return nil, true
+}
+
+// Errors runs the checks, returning every error found.
+func Errors(checks ...Check) (errs []scuter.Error) {
+	for _, check := range checks {
+		errs = check.run("", errs)
+	}
+	return errs
+}
+
+// Check validates a part of a model (see Field, Nested, and Each).
+type Check struct {
+	run func(prefix string, errs []scuter.Error) []scuter.Error
+}
+
+// Field checks the value against the rules in order, reporting the first which fails with Fields containing the
+// path (relative to that of any enclosing Nested or Each). Except for Required, rules consider an absent value (ie.
+// an empty string, a nil slice or pointer, or a zero time.Time) valid, so that optional values are only checked when
+// present. Numbers and booleans are never absent, such that Min(1) rejects 0.
+func Field[T any](path string, value T, rules ...Rule[T]) Check {
+	return Check{run: func(prefix string, errs []scuter.Error) []scuter.Error {
+		absent := isAbsent(value)
+		for _, rule := range rules {
+			if absent && !rule.required {
+				continue
+			}
+			if !rule.valid(value) {
+				failure := rule.failure
+				failure.Fields = []string{joinPath(prefix, path)}
+				return append(errs, failure)
+			}
+		}
+		return errs
+	}}
+}
+
+// Nested runs the checks with paths relative to the provided path (ie. a nested struct).
+func Nested(path string, checks ...Check) Check {
+	return Check{run: func(prefix string, errs []scuter.Error) []scuter.Error {
+		prefix = joinPath(prefix, path)
+		for _, check := range checks {
+			errs = check.run(prefix, errs)
+		}
+		return errs
+	}}
+}
+
+// Each runs the checks returned for each of the values with paths relative to that of the value (ie. "items[2]"),
+// such that a check with an empty path refers to the value itself.
+func Each[T any](path string, values []T, checks func(T) []Check) Check {
+	return Check{run: func(prefix string, errs []scuter.Error) []scuter.Error {
+		prefix = joinPath(prefix, path)
+		for x, value := range values {
+			element := prefix + "[" + strconv.Itoa(x) + "]"
+			for _, check := range checks(value) {
+				errs = check.run(element, errs)
+			}
+		}
+		return errs
+	}}
+}
+
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	case strings.HasPrefix(path, "["):
+		return prefix + path
+	default:
+		return prefix + "." + path
+	}
+}
+
+// isAbsent reports whether the value is missing from the model (see isZero), which, unlike a zero number or boolean,
+// can only be told for strings, slices, maps, pointers, interfaces, and values with an IsZero method.
+func isAbsent(value any) bool {
+	if _, ok := value.(interface{ IsZero() bool }); ok {
+		return isZero(value)
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Invalid, reflect.String, reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface:
+		return isZero(value)
+	}
+	return false
+}
+
+// isZero reports whether the value is the zero value, which includes strings containing only whitespace and empty
+// (but not nil) slices and maps, as well as values whose IsZero method says so (ie. time.Time).
+func isZero(value any) bool {
+	if zeroer, ok := value.(interface{ IsZero() bool }); ok {
+		return zeroer.IsZero()
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String:
+		return strings.TrimSpace(reflected.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return reflected.Len() == 0
+	}
+	return reflected.IsZero()
+}
@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/smarty/scuter"
+	"github.com/smarty/scuter/internal/should"
+)
+
+type order struct {
+	Email    string
+	Quantity int
+	Status   string
+	Customer struct{ Name string }
+	Items    []item
+	Tags     []string
+	Due      time.Time
+}
+type item struct {
+	SKU   string
+	Price float64
+}
+
+var (
+	now          = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+)
+
+func validateOrder(model order) []scuter.Error {
+	return Errors(
+		Field("body.email", model.Email, Required[string](), Matches(emailPattern, "an email address")),
+		Field("body.quantity", model.Quantity, Min(1), Max(10)),
+		Field("body.status", model.Status, OneOf("open", "closed")),
+		Nested("body.customer",
+			Field("name", model.Customer.Name, Required[string](), MinLength(2), MaxLength(5)),
+		),
+		Field("body.items", model.Items, Required[[]item](), MaxItems[item](2)),
+		Each("body.items", model.Items, func(value item) []Check {
+			return []Check{
+				Field("sku", value.SKU, Required[string]()),
+				Field("price", value.Price, Must(func(price float64) bool { return price > 0 }, ErrInvalid)),
+			}
+		}),
+		Each("body.tags", model.Tags, func(tag string) []Check {
+			return []Check{Field("", tag, MinLength(3))}
+		}),
+		Field("body.due", model.Due, After(now), Before(now.AddDate(1, 0, 0))),
+	)
+}
+
+func TestErrors_Valid(t *testing.T) {
+	model := order{Email: "a@b.c", Quantity: 5, Status: "open", Items: []item{{SKU: "x", Price: 1}}, Due: now.Add(time.Hour)}
+	model.Customer.Name = "Bob"
+
+	should.So(t, validateOrder(model), should.BeNil)
+}
+func TestErrors_Invalid(t *testing.T) {
+	model := order{
+		Email:    "nope",
+		Quantity: 11,
+		Status:   "pending",
+		Items:    []item{{SKU: "x", Price: 1}, {SKU: " ", Price: -1}, {SKU: "z", Price: 1}},
+		Tags:     []string{"abc", "ab"},
+		Due:      now,
+	}
+	model.Customer.Name = "Bartholomew"
+
+	errs := validateOrder(model)
+
+	should.So(t, errs, should.Equal, []scuter.Error{
+		{Fields: []string{"body.email"}, Name: "value-malformed", Message: "The value must be an email address."},
+		{Fields: []string{"body.quantity"}, Name: "value-too-large", Message: "The value must be at most 10."},
+		{Fields: []string{"body.status"}, Name: "value-not-allowed", Message: "The value must be one of: open, closed."},
+		{Fields: []string{"body.customer.name"}, Name: "value-too-long", Message: "The value must have at most 5 characters."},
+		{Fields: []string{"body.items"}, Name: "value-too-long", Message: "The value must have at most 2 items."},
+		{Fields: []string{"body.items[1].sku"}, Name: "value-required", Message: "The value is required."},
+		{Fields: []string{"body.items[1].price"}, Name: "value-invalid", Message: "The value is invalid."},
+		{Fields: []string{"body.tags[1]"}, Name: "value-too-short", Message: "The value must have at least 3 characters."},
+		{Fields: []string{"body.due"}, Name: "value-too-early", Message: "The value must be after 2026-01-01T00:00:00Z."},
+	})
+}
+func TestErrors_Missing(t *testing.T) {
+	errs := validateOrder(order{Quantity: 0, Items: []item{}})
+
+	should.So(t, errs, should.Equal, []scuter.Error{
+		{Fields: []string{"body.email"}, Name: "value-required", Message: "The value is required."},
+		{Fields: []string{"body.quantity"}, Name: "value-too-small", Message: "The value must be at least 1."},
+		{Fields: []string{"body.customer.name"}, Name: "value-required", Message: "The value is required."},
+		{Fields: []string{"body.items"}, Name: "value-required", Message: "The value is required."},
+	})
+}
+func TestErrors_ZeroNumbers(t *testing.T) {
+	errs := Errors(
+		Field("count", 0, Min(1)),
+		Field("size", 0, OneOf(1, 2, 3)),
+		Field("price", 0.0, Max(10.0)),
+		Field("enabled", false, Must(func(enabled bool) bool { return enabled }, ErrInvalid)),
+		Field("limit", 0, Required[int]()),
+	)
+
+	should.So(t, errs, should.Equal, []scuter.Error{
+		{Fields: []string{"count"}, Name: "value-too-small", Message: "The value must be at least 1."},
+		{Fields: []string{"size"}, Name: "value-not-allowed", Message: "The value must be one of: 1, 2, 3."},
+		{Fields: []string{"enabled"}, Name: "value-invalid", Message: "The value is invalid."},
+		{Fields: []string{"limit"}, Name: "value-required", Message: "The value is required."},
+	})
+}
+func TestErrors_FirstFailingRulePerField(t *testing.T) {
+	errs := Errors(Field("name", "x", MinLength(2), Matches(regexp.MustCompile(`^\d+$`), "")))
+
+	should.So(t, errs, should.Equal, []scuter.Error{
+		{Fields: []string{"name"}, Name: "value-too-short", Message: "The value must have at least 2 characters."},
+	})
+}
+func TestRule_Error(t *testing.T) {
+	custom := scuter.Error{Fields: []string{"ignored"}, ID: 42, Name: "missing-due-date", Message: "The due date is required."}
+
+	errs := Errors(Field("due_date", time.Time{}, Required[time.Time]().Error(custom)))
+
+	should.So(t, errs, should.Equal, []scuter.Error{
+		{Fields: []string{"due_date"}, ID: 42, Name: "missing-due-date", Message: "The due date is required."},
+	})
+	should.So(t, custom.Fields, should.Equal, []string{"ignored"})
+}
+func TestValidate(t *testing.T) {
+	result, ok := Validate(Field("a", "1", MaxLength(5)))
+	should.So(t, ok, should.BeTrue)
+	should.So(t, result, should.BeNil)
+
+	result, ok = Validate(Field("a", "123456", MaxLength(5)))
+	should.So(t, ok, should.BeFalse)
+	recorder := httptest.NewRecorder()
+	scuter.Flush(recorder, result)
+	should.So(t, recorder.Code, should.Equal, http.StatusUnprocessableEntity)
+	should.So(t, recorder.Body.String(), should.Equal,
+		`{"errors":[{"fields":["a"],"name":"value-too-long","message":"The value must have at most 5 characters."}]}`+"\n")
+}